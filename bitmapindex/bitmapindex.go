@@ -0,0 +1,270 @@
+package bitmapindex
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nathangreene3/bitmask/lmask"
+)
+
+// Index is a bitmap index over rows of columns. Each distinct value of
+// each column has a bitmask in which bit i is set if row i has that
+// value, so equality queries are answered by combining bitmasks. Rows
+// are non-negative integers and a row may have several values in a
+// column.
+//
+// Deleted rows are removed from an existence bitmask and every query is
+// restricted to the rows that exist. The bits of deleted rows are left
+// in the value bitmasks until Compact is called or the row is added
+// again.
+//
+// Every bitmask held by an index has the same bit capacity, which grows
+// by doubling as rows are added. Bitmasks returned by queries have bit
+// capacity Len.
+type Index[V comparable] struct {
+	rows            int
+	exists, deleted *lmask.LMask
+	columns         map[string]*column[V]
+}
+
+// column holds the bitmasks of a column.
+type column[V comparable] struct {
+	// present has bit i set if row i has any value in the column.
+	present *lmask.LMask
+
+	// values has the bitmask of each distinct value in the column.
+	values map[V]*lmask.LMask
+}
+
+// New returns an empty index.
+func New[V comparable]() *Index[V] {
+	return &Index[V]{exists: lmask.Zero(0), deleted: lmask.Zero(0), columns: make(map[string]*column[V])}
+}
+
+// --------------------------------------------------------------------
+// Row functionality
+// --------------------------------------------------------------------
+
+// Add records that a row has a value in a column. If the row was
+// deleted, then its previous values are forgotten. If the row is
+// negative, then Add panics with an error wrapping
+// lmask.ErrBitOutOfRange.
+func (a *Index[V]) Add(row int, columnName string, value V) *Index[V] {
+	if row < 0 {
+		panic(fmt.Errorf("%w: row %d is negative", lmask.ErrBitOutOfRange, row))
+	}
+
+	if bitCap := a.exists.BitCap(); bitCap <= row {
+		a.grow(max(row+1, 2*bitCap))
+	}
+
+	if a.deleted.MasksBit(row) {
+		a.purge(row)
+	}
+
+	a.rows = max(a.rows, row+1)
+	a.exists.SetBit(row)
+
+	c, ok := a.columns[columnName]
+	if !ok {
+		c = &column[V]{present: lmask.Zero(a.exists.BitCap()), values: make(map[V]*lmask.LMask)}
+		a.columns[columnName] = c
+	}
+
+	m, ok := c.values[value]
+	if !ok {
+		m = lmask.Zero(a.exists.BitCap())
+		c.values[value] = m
+	}
+
+	c.present.SetBit(row)
+	m.SetBit(row)
+	return a
+}
+
+// Compact removes the bits of deleted rows from every value bitmask.
+// Values and columns no row has any longer are removed.
+func (a *Index[V]) Compact() *Index[V] {
+	if a.deleted.Count() == 0 {
+		return a
+	}
+
+	for name, c := range a.columns {
+		if c.present.AndNot(a.deleted).Count() == 0 {
+			delete(a.columns, name)
+			continue
+		}
+
+		for v, m := range c.values {
+			if m.AndNot(a.deleted).Count() == 0 {
+				delete(c.values, v)
+			}
+		}
+	}
+
+	a.deleted = lmask.Zero(a.exists.BitCap())
+	return a
+}
+
+// Delete removes a row. Rows not added are ignored.
+func (a *Index[V]) Delete(row int) *Index[V] {
+	if a.exists.MasksBit(row) {
+		a.exists.ClrBit(row)
+		a.deleted.SetBit(row)
+	}
+
+	return a
+}
+
+// Has determines if a row has been added and not deleted.
+func (a *Index[V]) Has(row int) bool {
+	return a.exists.MasksBit(row)
+}
+
+// Len returns one more than the largest row added. It is the bit
+// capacity of bitmasks returned by queries.
+func (a *Index[V]) Len() int {
+	return a.rows
+}
+
+// Rows returns the rows that have been added and not deleted.
+func (a *Index[V]) Rows() []int {
+	return a.exists.Bits()
+}
+
+// --------------------------------------------------------------------
+// Query functionality
+// --------------------------------------------------------------------
+
+// All returns a bitmask of the rows that exist.
+func (a *Index[V]) All() *lmask.LMask {
+	return a.fit(a.exists.Copy())
+}
+
+// Eq returns a bitmask of the rows having a value in a column.
+func (a *Index[V]) Eq(columnName string, value V) *lmask.LMask {
+	m, ok := a.value(columnName, value)
+	if !ok {
+		return lmask.Zero(a.rows)
+	}
+
+	return a.fit(m.Copy().And(a.exists))
+}
+
+// In returns a bitmask of the rows having any of several values in a
+// column.
+func (a *Index[V]) In(columnName string, values ...V) *lmask.LMask {
+	r := lmask.Zero(a.exists.BitCap())
+	for i := 0; i < len(values); i++ {
+		if m, ok := a.value(columnName, values[i]); ok {
+			r.Or(m)
+		}
+	}
+
+	return a.fit(r.And(a.exists))
+}
+
+// NotEq returns a bitmask of the rows having some value in a column but
+// not a given value. Rows having no value in the column are excluded.
+func (a *Index[V]) NotEq(columnName string, value V) *lmask.LMask {
+	c, ok := a.columns[columnName]
+	if !ok {
+		return lmask.Zero(a.rows)
+	}
+
+	r := c.present.Copy().And(a.exists)
+	if m, ok := c.values[value]; ok {
+		r.AndNot(m)
+	}
+
+	return a.fit(r)
+}
+
+// --------------------------------------------------------------------
+// Count functionality
+// --------------------------------------------------------------------
+
+// Cardinality returns the number of distinct values a column has among
+// the rows that exist.
+func (a *Index[V]) Cardinality(columnName string) int {
+	var n int
+	if c, ok := a.columns[columnName]; ok {
+		for _, m := range c.values {
+			if m.Intersects(a.exists) {
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+// Columns returns the names of the columns in increasing order.
+func (a *Index[V]) Columns() []string {
+	names := make([]string, 0, len(a.columns))
+	for name := range a.columns {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Count returns the number of rows that exist.
+func (a *Index[V]) Count() int {
+	return a.exists.Count()
+}
+
+// CountEq returns the number of rows having a value in a column.
+func (a *Index[V]) CountEq(columnName string, value V) int {
+	m, ok := a.value(columnName, value)
+	if !ok {
+		return 0
+	}
+
+	return m.IntersectionCount(a.exists)
+}
+
+// --------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------
+
+// fit sets the bit capacity of a query result to the number of rows.
+func (a *Index[V]) fit(m *lmask.LMask) *lmask.LMask {
+	return m.SetBitCap(a.rows)
+}
+
+// grow sets the bit capacity of every bitmask.
+func (a *Index[V]) grow(bitCap int) {
+	a.exists.SetBitCap(bitCap)
+	a.deleted.SetBitCap(bitCap)
+	for _, c := range a.columns {
+		c.present.SetBitCap(bitCap)
+		for _, m := range c.values {
+			m.SetBitCap(bitCap)
+		}
+	}
+}
+
+// purge removes a deleted row from every bitmask.
+func (a *Index[V]) purge(row int) {
+	for _, c := range a.columns {
+		c.present.ClrBit(row)
+		for _, m := range c.values {
+			m.ClrBit(row)
+		}
+	}
+
+	a.deleted.ClrBit(row)
+}
+
+// value returns the bitmask of a value in a column.
+func (a *Index[V]) value(columnName string, value V) (*lmask.LMask, bool) {
+	c, ok := a.columns[columnName]
+	if !ok {
+		return nil, false
+	}
+
+	m, ok := c.values[value]
+	return m, ok
+}
@@ -0,0 +1,167 @@
+package bitmapindex
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/nathangreene3/bitmask/lmask"
+)
+
+func TestQueries(t *testing.T) {
+	a := New[string]().
+		Add(0, "color", "red").Add(0, "size", "S").
+		Add(1, "color", "blue").Add(1, "size", "M").
+		Add(2, "color", "red").Add(2, "size", "L").
+		Add(4, "color", "green")
+
+	type testCase struct {
+		rec *lmask.LMask
+		exp []int
+	}
+
+	tests := []testCase{
+		{rec: a.Eq("color", "red"), exp: []int{0, 2}},
+		{rec: a.Eq("color", "pink"), exp: []int{}},
+		{rec: a.Eq("shape", "red"), exp: []int{}},
+		{rec: a.In("color", "red", "green", "pink"), exp: []int{0, 2, 4}},
+		{rec: a.In("color"), exp: []int{}},
+		{rec: a.NotEq("color", "red"), exp: []int{1, 4}},
+		{rec: a.NotEq("size", "M"), exp: []int{0, 2}},
+		{rec: a.NotEq("size", "XL"), exp: []int{0, 1, 2}},
+		{rec: a.Eq("color", "red").And(a.NotEq("size", "S")), exp: []int{2}},
+		{rec: a.All(), exp: []int{0, 1, 2, 4}},
+	}
+
+	for _, tc := range tests {
+		if rec := tc.rec.Bits(); !slices.Equal(tc.exp, rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", tc.exp, rec)
+		}
+
+		if exp, rec := 5, tc.rec.BitCap(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+	}
+
+	if exp, rec := []string{"color", "size"}, a.Columns(); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+	}
+
+	if exp, rec := 3, a.Cardinality("color"); exp != rec {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+
+	if exp, rec := 2, a.CountEq("color", "red"); exp != rec {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, lmask.ErrBitOutOfRange) {
+			t.Errorf("\nexpected %v\nreceived %v\n", lmask.ErrBitOutOfRange, err)
+		}
+	}()
+
+	a.Add(-1, "color", "red")
+}
+
+func TestDelete(t *testing.T) {
+	a := New[int]().Add(0, "n", 1).Add(1, "n", 1).Add(2, "n", 2)
+	a.Delete(1).Delete(2).Delete(7)
+	if exp, rec := []int{0}, a.Rows(); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+	}
+
+	if exp, rec := 1, a.CountEq("n", 1); exp != rec {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+
+	if exp, rec := 1, a.Cardinality("n"); exp != rec {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+
+	// Adding a deleted row forgets its previous values.
+	a.Add(1, "n", 3)
+	if exp, rec := []int{0}, a.Eq("n", 1).Bits(); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+	}
+
+	if exp, rec := []int{1}, a.NotEq("n", 1).Bits(); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+	}
+
+	a.Compact()
+	if _, ok := a.value("n", 2); ok {
+		t.Errorf("\nexpected value 2 to be removed\n")
+	}
+
+	if !a.Has(0) || !a.Has(1) || a.Has(2) || a.Count() != 2 || a.Len() != 3 {
+		t.Errorf("\nexpected rows [0 1]\nreceived %v\n", a.Rows())
+	}
+
+	// Compacting removes columns no row has.
+	a.Add(2, "m", 4).Add(3, "m", 5).Delete(2).Delete(3)
+	if exp, rec := []string{"m", "n"}, a.Columns(); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %q\nreceived %q\n", exp, rec)
+	}
+
+	a.Compact()
+	if exp, rec := []string{"n"}, a.Columns(); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %q\nreceived %q\n", exp, rec)
+	}
+
+	if exp, rec := 0, a.Cardinality("m"); exp != rec {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	var (
+		r      = rand.New(rand.NewSource(0))
+		a      = New[int]()
+		values = make(map[int]int) // Row to value of a single column
+	)
+
+	for i := 0; i < 2000; i++ {
+		row := r.Intn(500)
+		switch r.Intn(4) {
+		case 0:
+			a.Delete(row)
+			delete(values, row)
+		default:
+			if _, ok := values[row]; ok {
+				// Keep one value per row.
+				continue
+			}
+
+			v := r.Intn(10)
+			a.Add(row, "v", v)
+			values[row] = v
+		}
+
+		if i%300 == 0 {
+			a.Compact()
+		}
+	}
+
+	for v := 0; v < 10; v++ {
+		var exp []int
+		for row := 0; row < a.Len(); row++ {
+			if w, ok := values[row]; ok && w == v {
+				exp = append(exp, row)
+			}
+		}
+
+		if rec := a.Eq("v", v).Bits(); !slices.Equal(exp, rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+		}
+
+		if exp, rec := len(values)-len(exp), a.NotEq("v", v).Count(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+	}
+
+	if exp, rec := len(values), a.In("v", 0, 1, 2, 3, 4, 5, 6, 7, 8, 9).Count(); exp != rec {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+}
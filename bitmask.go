@@ -1,6 +1,12 @@
 package bitmask
 
-import "math/bits"
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+
+	"github.com/nathangreene3/bitmask/internal/bounds"
+)
 
 const (
 	// BitCap is the maximum number of bits in a bitmask.
@@ -10,6 +16,12 @@ const (
 	Max = 1<<BitCap - 1
 )
 
+// ErrBitOutOfRange indicates a bit is not on range [0, BitCap). Every
+// function taking a range of bits [lo, hi) panics with it if the range
+// is non-empty and not within [0, BitCap). An empty range, where
+// hi <= lo, is always allowed.
+var ErrBitOutOfRange = bounds.ErrBitOutOfRange
+
 // -------------------------------------------------------------------------
 // Bitwise functionality
 // -------------------------------------------------------------------------
@@ -58,6 +70,70 @@ func XOr(a, b uint) uint {
 // Set functionality
 // -------------------------------------------------------------------------
 
+// All returns an iterator over the set bits in a bitmask in increasing
+// order.
+func All(a uint) iter.Seq[int] {
+	return AllRange(a, 0, BitCap)
+}
+
+// AllInRange determines if every bit on range [lo, hi) is set. An empty
+// range is always set.
+func AllInRange(a uint, lo, hi int) bool {
+	m := rangeMask(lo, hi)
+	return a&m == m
+}
+
+// AllRange returns an iterator over the set bits on range [lo, hi) in a
+// bitmask in increasing order.
+func AllRange(a uint, lo, hi int) iter.Seq[int] {
+	if err := checkRange(lo, hi); err != nil {
+		panic(err)
+	}
+
+	return func(yield func(int) bool) {
+		if hi <= lo {
+			return
+		}
+
+		for bit := NextBit(a, lo-1); bit < hi; bit = NextBit(a, bit) {
+			if !yield(bit) {
+				return
+			}
+		}
+	}
+}
+
+// AnyInRange determines if any bit on range [lo, hi) is set.
+func AnyInRange(a uint, lo, hi int) bool {
+	return a&rangeMask(lo, hi) != 0
+}
+
+// Backward returns an iterator over the set bits in a bitmask in
+// decreasing order.
+func Backward(a uint) iter.Seq[int] {
+	return BackwardRange(a, 0, BitCap)
+}
+
+// BackwardRange returns an iterator over the set bits on range [lo, hi)
+// in a bitmask in decreasing order.
+func BackwardRange(a uint, lo, hi int) iter.Seq[int] {
+	if err := checkRange(lo, hi); err != nil {
+		panic(err)
+	}
+
+	return func(yield func(int) bool) {
+		if hi <= lo {
+			return
+		}
+
+		for bit := PrevBit(a, hi); lo <= bit; bit = PrevBit(a, bit) {
+			if !yield(bit) {
+				return
+			}
+		}
+	}
+}
+
 // Bits returns the bits that are set in a bitmask.
 func Bits(a uint) []int {
 	bits := make([]int, 0, BitCap)
@@ -87,11 +163,120 @@ func ClrBits(a uint, bits ...int) uint {
 	return a
 }
 
+// ClrRange unsets the bits on range [lo, hi).
+func ClrRange(a uint, lo, hi int) uint {
+	return a &^ rangeMask(lo, hi)
+}
+
 // Count ...
 func Count(a uint) int {
 	return bits.OnesCount(a)
 }
 
+// CountRange returns the number of bits set on range [lo, hi).
+func CountRange(a uint, lo, hi int) int {
+	return bits.OnesCount(a & rangeMask(lo, hi))
+}
+
+// Deposit returns the low bits of a scattered to the bits set in mask,
+// in order. That is, the ith lowest bit of a is moved to the ith lowest
+// set bit of mask. This is the PDEP instruction of BMI2.
+func Deposit(a, mask uint) uint {
+	var d uint
+	for mask != 0 {
+		// Deposit a run of n set bits beginning at bit p at once.
+		p := bits.TrailingZeros(mask)
+		n := bits.TrailingZeros(^(mask >> p))
+		d |= (a & (1<<n - 1)) << p
+		a >>= n
+		mask &^= (1<<n - 1) << p
+	}
+
+	return d
+}
+
+// Dice returns the Sørensen–Dice coefficient of a and b, which is twice
+// the number of bits set in both divided by the sum of the numbers of
+// bits set in each. If no bits are set in either, then it is one.
+func Dice(a, b uint) float64 {
+	sum := bits.OnesCount(a) + bits.OnesCount(b)
+	if sum == 0 {
+		return 1
+	}
+
+	return float64(2*bits.OnesCount(a&b)) / float64(sum)
+}
+
+// Extract returns the bits of a selected by mask gathered into the low
+// bits, in order. That is, the bit of a at the ith lowest set bit of
+// mask is moved to bit i. This is the PEXT instruction of BMI2.
+func Extract(a, mask uint) uint {
+	var (
+		e uint
+		i int
+	)
+
+	for mask != 0 {
+		// Extract a run of n set bits beginning at bit p at once.
+		p := bits.TrailingZeros(mask)
+		n := bits.TrailingZeros(^(mask >> p))
+		e |= (a >> p & (1<<n - 1)) << i
+		i += n
+		mask &^= (1<<n - 1) << p
+	}
+
+	return e
+}
+
+// FlipRange inverts the bits on range [lo, hi).
+func FlipRange(a uint, lo, hi int) uint {
+	return a ^ rangeMask(lo, hi)
+}
+
+// HammingDistance returns the number of bits set in exactly one of a
+// and b.
+func HammingDistance(a, b uint) int {
+	return bits.OnesCount(a ^ b)
+}
+
+// IntersectionCount returns the number of bits set in both a and b.
+func IntersectionCount(a, b uint) int {
+	return bits.OnesCount(a & b)
+}
+
+// Intersects determines if any bit is set in both a and b.
+func Intersects(a, b uint) bool {
+	return a&b != 0
+}
+
+// IsDisjoint determines if no bit is set in both a and b.
+func IsDisjoint(a, b uint) bool {
+	return a&b == 0
+}
+
+// IsSubset determines if every bit set in a is set in b.
+func IsSubset(a, b uint) bool {
+	return a&^b == 0
+}
+
+// Jaccard returns the Jaccard index of a and b, which is the number of
+// bits set in both divided by the number of bits set in either. If no
+// bits are set in either, then it is one.
+func Jaccard(a, b uint) float64 {
+	or := bits.OnesCount(a | b)
+	if or == 0 {
+		return 1
+	}
+
+	return float64(bits.OnesCount(a&b)) / float64(or)
+}
+
+// LSh returns a bitmask with all bits shifted to the left a given
+// number of bits.
+func LSh(a uint, bits int) uint {
+	return a << bits
+}
+
 // Masks ...
 func Masks(a, b uint) bool {
 	return a&b == b
@@ -115,6 +300,37 @@ func PrevBit(a uint, bit int) int {
 	return BitCap - bits.LeadingZeros(a<<bit>>bit) - 1
 }
 
+// RSh returns a bitmask with all bits shifted to the right a given
+// number of bits.
+func RSh(a uint, bits int) uint {
+	return a >> bits
+}
+
+// Reverse returns a bitmask with the order of its bits reversed. That
+// is, bit i is moved to bit BitCap-1-i.
+func Reverse(a uint) uint {
+	return bits.Reverse(a)
+}
+
+// ReverseBytes returns a bitmask with the order of its bytes reversed.
+func ReverseBytes(a uint) uint {
+	return bits.ReverseBytes(a)
+}
+
+// RotL returns a bitmask with all bits rotated to the left k bits. Bits
+// shifted beyond the bit capacity are moved to the lowest bits. A
+// negative k rotates to the right.
+func RotL(a uint, k int) uint {
+	return bits.RotateLeft(a, k)
+}
+
+// RotR returns a bitmask with all bits rotated to the right k bits.
+// Bits shifted below zero are moved to the highest bits. A negative k
+// rotates to the left.
+func RotR(a uint, k int) uint {
+	return bits.RotateLeft(a, -k)
+}
+
 // Set ...
 func Set(a, b uint) uint {
 	return a | b
@@ -134,6 +350,16 @@ func SetBits(a uint, bits ...int) uint {
 	return a
 }
 
+// SetRange sets the bits on range [lo, hi).
+func SetRange(a uint, lo, hi int) uint {
+	return a | rangeMask(lo, hi)
+}
+
+// UnionCount returns the number of bits set in either a or b.
+func UnionCount(a, b uint) int {
+	return bits.OnesCount(a | b)
+}
+
 // -------------------------------------------------------------------------
 // Helper functionality
 // -------------------------------------------------------------------------
@@ -149,3 +375,28 @@ func clamp(n, a, b int) int {
 		return n
 	}
 }
+
+// checkRange returns an error wrapping ErrBitOutOfRange if a non-empty
+// range [lo, hi) is not within [0, BitCap).
+func checkRange(lo, hi int) error {
+	if lo < hi && (lo < 0 || BitCap < hi) {
+		return fmt.Errorf("%w: [%d, %d) not within [0, %d)", ErrBitOutOfRange, lo, hi, BitCap)
+	}
+
+	return nil
+}
+
+// rangeMask returns a bitmask with the bits on range [lo, hi) set. It
+// panics if checkRange fails.
+func rangeMask(lo, hi int) uint {
+	if err := checkRange(lo, hi); err != nil {
+		panic(err)
+	}
+
+	lo, hi = clamp(lo, 0, BitCap), clamp(hi, 0, BitCap)
+	if hi <= lo {
+		return 0
+	}
+
+	return uint(Max) >> (BitCap - hi + lo) << lo
+}
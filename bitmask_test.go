@@ -1,10 +1,277 @@
 package bitmask
 
 import (
+	"errors"
 	"math"
+	"slices"
 	"testing"
 )
 
+// -------------------------------------------------------------------------
+// Tests
+// -------------------------------------------------------------------------
+
+func TestAllBackward(t *testing.T) {
+	type testCase struct {
+		a           uint
+		lo, hi      int
+		expAll      []int
+		expBackward []int
+	}
+
+	tcs := []testCase{
+		{
+			a:           0,
+			lo:          0,
+			hi:          BitCap,
+			expAll:      []int{},
+			expBackward: []int{},
+		},
+		{
+			a:           SetBits(0, 0, 1, BitCap-1),
+			lo:          0,
+			hi:          BitCap,
+			expAll:      []int{0, 1, BitCap - 1},
+			expBackward: []int{BitCap - 1, 1, 0},
+		},
+		{
+			a:           SetBits(0, 0, 1, BitCap-1),
+			lo:          1,
+			hi:          BitCap - 1,
+			expAll:      []int{1},
+			expBackward: []int{1},
+		},
+		{
+			a:           Max,
+			lo:          -5,
+			hi:          -10,
+			expAll:      []int{},
+			expBackward: []int{},
+		},
+		{
+			a:           Max,
+			lo:          BitCap + 5,
+			hi:          BitCap + 2,
+			expAll:      []int{},
+			expBackward: []int{},
+		},
+	}
+
+	for _, tc := range tcs {
+		if rec := slices.Collect(AllRange(tc.a, tc.lo, tc.hi)); !slices.Equal(tc.expAll, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", tc.expAll, rec)
+		}
+
+		if rec := slices.Collect(BackwardRange(tc.a, tc.lo, tc.hi)); !slices.Equal(tc.expBackward, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", tc.expBackward, rec)
+		}
+
+		if exp, rec := Bits(tc.a), slices.Collect(All(tc.a)); !slices.Equal(exp, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		exp := Bits(tc.a)
+		slices.Reverse(exp)
+		if rec := slices.Collect(Backward(tc.a)); !slices.Equal(exp, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	var (
+		a    = SetBits(0, 0, 2, 3, BitCap/2, BitCap/2+1, BitCap-1)
+		ends = []int{-1, 0, 1, 3, BitCap / 2, BitCap - 1, BitCap, BitCap + 1}
+	)
+
+	for _, lo := range ends {
+		for _, hi := range ends {
+			if lo < hi && (lo < 0 || BitCap < hi) {
+				for name, f := range map[string]func(){
+					"AllInRange":    func() { AllInRange(a, lo, hi) },
+					"AllRange":      func() { AllRange(a, lo, hi) },
+					"AnyInRange":    func() { AnyInRange(a, lo, hi) },
+					"BackwardRange": func() { BackwardRange(a, lo, hi) },
+					"ClrRange":      func() { ClrRange(a, lo, hi) },
+					"CountRange":    func() { CountRange(a, lo, hi) },
+					"FlipRange":     func() { FlipRange(a, lo, hi) },
+					"SetRange":      func() { SetRange(a, lo, hi) },
+				} {
+					func() {
+						defer func() {
+							if err, _ := recover().(error); !errors.Is(err, ErrBitOutOfRange) {
+								t.Errorf("\nexpected %v from %s on [%d, %d)\nreceived %v\n", ErrBitOutOfRange, name, lo, hi, err)
+							}
+						}()
+
+						f()
+					}()
+				}
+
+				continue
+			}
+
+			var (
+				count                 int
+				setExp, clrExp, flExp = a, a, a
+				anyExp, allExp        = false, true
+			)
+
+			for bit := lo; bit < hi; bit++ {
+				if MasksBit(a, bit) {
+					count++
+					anyExp = true
+				} else {
+					allExp = false
+				}
+
+				setExp = SetBit(setExp, bit)
+				clrExp = ClrBit(clrExp, bit)
+				flExp ^= 1 << bit
+			}
+
+			if rec := CountRange(a, lo, hi); count != rec {
+				t.Errorf("\nexpected count %d on [%d, %d)\nreceived %d\n", count, lo, hi, rec)
+			}
+
+			if rec := AnyInRange(a, lo, hi); anyExp != rec {
+				t.Errorf("\nexpected any %t on [%d, %d)\nreceived %t\n", anyExp, lo, hi, rec)
+			}
+
+			if rec := AllInRange(a, lo, hi); allExp != rec {
+				t.Errorf("\nexpected all %t on [%d, %d)\nreceived %t\n", allExp, lo, hi, rec)
+			}
+
+			if rec := SetRange(a, lo, hi); setExp != rec {
+				t.Errorf("\nexpected %b on [%d, %d)\nreceived %b\n", setExp, lo, hi, rec)
+			}
+
+			if rec := ClrRange(a, lo, hi); clrExp != rec {
+				t.Errorf("\nexpected %b on [%d, %d)\nreceived %b\n", clrExp, lo, hi, rec)
+			}
+
+			if rec := FlipRange(a, lo, hi); flExp != rec {
+				t.Errorf("\nexpected %b on [%d, %d)\nreceived %b\n", flExp, lo, hi, rec)
+			}
+		}
+	}
+}
+
+func TestRotReverse(t *testing.T) {
+	for _, a := range []uint{0, 1, 0xf0, 1<<(BitCap-1) | 5, Max, 0x0102030405060708 & Max} {
+		for _, k := range []int{-BitCap - 1, -1, 0, 1, 3, BitCap - 1, BitCap, 2*BitCap + 1} {
+			expL, expR := uint(0), uint(0)
+			for bit := 0; bit < BitCap; bit++ {
+				if MasksBit(a, bit) {
+					expL |= 1 << (((bit+k)%BitCap + BitCap) % BitCap)
+					expR |= 1 << (((bit-k)%BitCap + BitCap) % BitCap)
+				}
+			}
+
+			if rec := RotL(a, k); expL != rec {
+				t.Errorf("\nexpected %b\nreceived %b\n", expL, rec)
+			}
+
+			if rec := RotR(a, k); expR != rec {
+				t.Errorf("\nexpected %b\nreceived %b\n", expR, rec)
+			}
+		}
+
+		expRev, expBytes := uint(0), uint(0)
+		for bit := 0; bit < BitCap; bit++ {
+			if MasksBit(a, bit) {
+				expRev |= 1 << (BitCap - 1 - bit)
+				expBytes |= 1 << (BitCap - 8 - bit/8*8 + bit%8)
+			}
+		}
+
+		if rec := Reverse(a); expRev != rec {
+			t.Errorf("\nexpected %b\nreceived %b\n", expRev, rec)
+		}
+
+		if rec := ReverseBytes(a); expBytes != rec {
+			t.Errorf("\nexpected %b\nreceived %b\n", expBytes, rec)
+		}
+
+		if exp, rec := LSh(a, 3), RotL(a, 3)&^7; exp != rec {
+			t.Errorf("\nexpected %b\nreceived %b\n", exp, rec)
+		}
+	}
+}
+
+func TestExtractDeposit(t *testing.T) {
+	vals := []uint{0, 1, 0xf0, 1<<(BitCap-1) | 5, Max, 0x9abcdef0 & Max, Max &^ 0x1000}
+	for _, a := range vals {
+		for _, m := range vals {
+			// Move bits one at a time.
+			expExt, expDep := uint(0), uint(0)
+			for bit, i := 0, 0; bit < BitCap; bit++ {
+				if MasksBit(m, bit) {
+					if MasksBit(a, bit) {
+						expExt |= 1 << i
+					}
+
+					if MasksBit(a, i) {
+						expDep |= 1 << bit
+					}
+
+					i++
+				}
+			}
+
+			if rec := Extract(a, m); expExt != rec {
+				t.Errorf("\nexpected %b\nreceived %b\n", expExt, rec)
+			}
+
+			if rec := Deposit(a, m); expDep != rec {
+				t.Errorf("\nexpected %b\nreceived %b\n", expDep, rec)
+			}
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	vals := []uint{0, 1, 0xf0, 0x3c, 1<<(BitCap-1) | 5, Max}
+	for _, a := range vals {
+		for _, b := range vals {
+			and, or := Count(a&b), Count(a|b)
+			if exp, rec := Count(a^b), HammingDistance(a, b); exp != rec {
+				t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+			}
+
+			if rec := IntersectionCount(a, b); and != rec {
+				t.Errorf("\nexpected %d\nreceived %d\n", and, rec)
+			}
+
+			if rec := UnionCount(a, b); or != rec {
+				t.Errorf("\nexpected %d\nreceived %d\n", or, rec)
+			}
+
+			if exp, rec := a&b != 0, Intersects(a, b); exp != rec || exp == IsDisjoint(a, b) {
+				t.Errorf("\nexpected intersects %t\nreceived %t\n", exp, rec)
+			}
+
+			if exp, rec := a|b == b, IsSubset(a, b); exp != rec {
+				t.Errorf("\nexpected subset %t\nreceived %t\n", exp, rec)
+			}
+
+			expJ, expD := 1.0, 1.0
+			if 0 < or {
+				expJ = float64(and) / float64(or)
+				expD = float64(2*and) / float64(Count(a)+Count(b))
+			}
+
+			if rec := Jaccard(a, b); expJ != rec {
+				t.Errorf("\nexpected %f\nreceived %f\n", expJ, rec)
+			}
+
+			if rec := Dice(a, b); expD != rec {
+				t.Errorf("\nexpected %f\nreceived %f\n", expD, rec)
+			}
+		}
+	}
+}
+
 // -------------------------------------------------------------------------
 // Applications
 // -------------------------------------------------------------------------
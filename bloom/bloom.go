@@ -0,0 +1,311 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/nathangreene3/bitmask/lmask"
+)
+
+const (
+	// binaryVersion is the version of the binary encoding written by
+	// MarshalBinary.
+	binaryVersion = 1
+
+	// MaxHashes is the maximum number of hashes of a filter. It bounds
+	// the work of adding and testing keys, including for filters decoded
+	// from untrusted data.
+	MaxHashes = 64
+
+	// fnvOffset and fnvPrime are the 64-bit FNV-1a parameters.
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+var (
+	// ErrIncompatible indicates an operation has been applied on two
+	// filters not having the same bit capacity and number of hashes.
+	ErrIncompatible = errors.New("incompatible filters")
+
+	// ErrInvalidEncoding indicates data could not be decoded into a
+	// filter.
+	ErrInvalidEncoding = lmask.ErrInvalidEncoding
+
+	// ErrInvalidParameters indicates the parameters of a filter or of its
+	// estimates are not valid.
+	ErrInvalidParameters = errors.New("invalid parameters")
+)
+
+// Filter is a Bloom filter. Each key added sets k bits of a bitmask
+// chosen by double hashing the key. A key tested is reported as
+// possibly added if all of its k bits are set, and as never added
+// otherwise, so false positives are possible but false negatives are
+// not.
+type Filter struct {
+	k    int
+	bits *lmask.LMask
+}
+
+// --------------------------------------------------------------------
+// Constructors
+// --------------------------------------------------------------------
+
+// New returns an empty filter setting k bits of m bits per key. If m is
+// not positive or k is not on range [1, MaxHashes], then New panics with
+// an error wrapping ErrInvalidParameters.
+func New(m, k int) *Filter {
+	if m <= 0 || k <= 0 || MaxHashes < k {
+		panic(fmt.Errorf("%w: m = %d, k = %d", ErrInvalidParameters, m, k))
+	}
+
+	return &Filter{k: k, bits: lmask.Zero(m)}
+}
+
+// NewWithEstimates returns an empty filter sized to hold n keys with a
+// false positive rate of at most p. See Estimate.
+func NewWithEstimates(n int, p float64) *Filter {
+	return New(Estimate(n, p))
+}
+
+// Estimate returns the number of bits m and the number of hashes k that
+// minimize the size of a filter holding n keys with a false positive
+// rate of at most p. The number of hashes is at most MaxHashes. If n is
+// not positive or p is not on range (0, 1), then Estimate panics with an
+// error wrapping ErrInvalidParameters.
+func Estimate(n int, p float64) (m, k int) {
+	if n <= 0 || !(0 < p && p < 1) {
+		panic(fmt.Errorf("%w: n = %d, p = %g", ErrInvalidParameters, n, p))
+	}
+
+	m = int(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k = min(max(int(math.Round(float64(m)/float64(n)*math.Ln2)), 1), MaxHashes)
+	return m, k
+}
+
+// --------------------------------------------------------------------
+// Filter functionality
+// --------------------------------------------------------------------
+
+// Add adds a key.
+func (a *Filter) Add(key []byte) *Filter {
+	add(a, key)
+	return a
+}
+
+// AddString adds a key.
+func (a *Filter) AddString(key string) *Filter {
+	add(a, key)
+	return a
+}
+
+// BitCap returns the number of bits m.
+func (a *Filter) BitCap() int {
+	return a.bits.BitCap()
+}
+
+// Compatible determines if two filters have the same bit capacity and
+// number of hashes, so they may be combined.
+func (a *Filter) Compatible(b *Filter) bool {
+	return a.k == b.k && a.bits.BitCap() == b.bits.BitCap()
+}
+
+// Copy returns a copy of a filter.
+func (a *Filter) Copy() *Filter {
+	return &Filter{k: a.k, bits: a.bits.Copy()}
+}
+
+// Count returns the estimated number of distinct keys added. A filter
+// having every bit set returns positive infinity.
+func (a *Filter) Count() float64 {
+	m := float64(a.bits.BitCap())
+	return -m / float64(a.k) * math.Log1p(-float64(a.bits.Count())/m)
+}
+
+// Equals determines if two filters are compatible and have the same
+// bits set.
+func (a *Filter) Equals(b *Filter) bool {
+	return a.k == b.k && a.bits.Equals(b.bits)
+}
+
+// FalsePositiveRate returns the estimated probability that a key not
+// added is reported as possibly added, given the bits currently set.
+func (a *Filter) FalsePositiveRate() float64 {
+	return math.Pow(float64(a.bits.Count())/float64(a.bits.BitCap()), float64(a.k))
+}
+
+// Hashes returns the number of hashes k.
+func (a *Filter) Hashes() int {
+	return a.k
+}
+
+// Intersect keeps only the bits set in both a and b. The result may
+// report more keys as possibly added than a filter built from only the
+// keys added to both. If the filters are not compatible, then Intersect
+// panics with an error wrapping ErrIncompatible.
+func (a *Filter) Intersect(b *Filter) *Filter {
+	if !a.Compatible(b) {
+		panic(incompatible(a, b))
+	}
+
+	a.bits.And(b.bits)
+	return a
+}
+
+// LMask returns a copy of the bits of a filter.
+func (a *Filter) LMask() *lmask.LMask {
+	return a.bits.Copy()
+}
+
+// Test determines if a key was possibly added.
+func (a *Filter) Test(key []byte) bool {
+	return test(a, key)
+}
+
+// TestAndAdd determines if a key was possibly added and then adds it.
+func (a *Filter) TestAndAdd(key []byte) bool {
+	return testAndAdd(a, key)
+}
+
+// TestAndAddString determines if a key was possibly added and then adds
+// it.
+func (a *Filter) TestAndAddString(key string) bool {
+	return testAndAdd(a, key)
+}
+
+// TestString determines if a key was possibly added.
+func (a *Filter) TestString(key string) bool {
+	return test(a, key)
+}
+
+// Union adds every key added to b. If the filters are not compatible,
+// then Union panics with an error wrapping ErrIncompatible.
+func (a *Filter) Union(b *Filter) *Filter {
+	if !a.Compatible(b) {
+		panic(incompatible(a, b))
+	}
+
+	a.bits.Or(b.bits)
+	return a
+}
+
+// --------------------------------------------------------------------
+// Encoding functionality
+// --------------------------------------------------------------------
+
+// MarshalBinary returns a binary encoding of a filter. The encoding is
+// a version byte, the number of hashes as an unsigned varint, and then
+// the binary encoding of the bits.
+func (a *Filter) MarshalBinary() ([]byte, error) {
+	bits, err := a.bits.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 0, 1+binary.MaxVarintLen64+len(bits))
+	b = append(b, binaryVersion)
+	b = binary.AppendUvarint(b, uint64(a.k))
+	return append(b, bits...), nil
+}
+
+// UnmarshalBinary decodes data written by MarshalBinary into a filter.
+// An error wrapping ErrInvalidEncoding is returned if the data is
+// malformed.
+func (a *Filter) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: no data", ErrInvalidEncoding)
+	}
+
+	if data[0] != binaryVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidEncoding, data[0])
+	}
+
+	k, n := binary.Uvarint(data[1:])
+	if n <= 0 || k == 0 || MaxHashes < k {
+		return fmt.Errorf("%w: number of hashes not on range [1, %d]", ErrInvalidEncoding, MaxHashes)
+	}
+
+	bits := new(lmask.LMask)
+	if err := bits.UnmarshalBinary(data[1+n:]); err != nil {
+		return err
+	}
+
+	if bits.BitCap() == 0 {
+		return fmt.Errorf("%w: no bits", ErrInvalidEncoding)
+	}
+
+	a.k, a.bits = int(k), bits
+	return nil
+}
+
+// --------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------
+
+// add sets the bits of a key.
+func add[T []byte | string](a *Filter, key T) {
+	h1, h2 := hash(key)
+	for i := 0; i < a.k; i++ {
+		a.bits.SetBit(index(a, h1, h2, i))
+	}
+}
+
+// hash returns two hashes of a key for double hashing. The first is the
+// FNV-1a hash of the key and the second is derived from the first by
+// the SplitMix64 finalizer. The second is odd, so it is never zero.
+func hash[T []byte | string](key T) (uint64, uint64) {
+	h1 := uint64(fnvOffset)
+	for i := 0; i < len(key); i++ {
+		h1 ^= uint64(key[i])
+		h1 *= fnvPrime
+	}
+
+	h2 := h1
+	h2 = (h2 ^ h2>>30) * 0xbf58476d1ce4e5b9
+	h2 = (h2 ^ h2>>27) * 0x94d049bb133111eb
+	h2 ^= h2 >> 31
+	return h1, h2 | 1
+}
+
+// incompatible returns an error indicating two filters are not
+// compatible.
+func incompatible(a, b *Filter) error {
+	return fmt.Errorf("%w: m = %d, k = %d and m = %d, k = %d", ErrIncompatible, a.bits.BitCap(), a.k, b.bits.BitCap(), b.k)
+}
+
+// index returns the ith bit of a key having the given hashes.
+func index(a *Filter, h1, h2 uint64, i int) int {
+	return int((h1 + uint64(i)*h2) % uint64(a.bits.BitCap()))
+}
+
+// test determines if every bit of a key is set.
+func test[T []byte | string](a *Filter, key T) bool {
+	h1, h2 := hash(key)
+	for i := 0; i < a.k; i++ {
+		if !a.bits.MasksBit(index(a, h1, h2, i)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// testAndAdd determines if every bit of a key is set and then sets
+// them.
+func testAndAdd[T []byte | string](a *Filter, key T) bool {
+	var (
+		h1, h2 = hash(key)
+		ok     = true
+	)
+
+	for i := 0; i < a.k; i++ {
+		bit := index(a, h1, h2, i)
+		if !a.bits.MasksBit(bit) {
+			ok = false
+			a.bits.SetBit(bit)
+		}
+	}
+
+	return ok
+}
@@ -0,0 +1,211 @@
+package bloom
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/nathangreene3/bitmask/lmask"
+)
+
+func TestEstimate(t *testing.T) {
+	type testCase struct {
+		n    int
+		p    float64
+		expM int
+		expK int
+	}
+
+	tests := []testCase{
+		{n: 1, p: 0.5, expM: 2, expK: 1},
+		{n: 1000, p: 0.01, expM: 9586, expK: 7},
+		{n: 1000000, p: 0.001, expM: 14377588, expK: 10},
+		{n: 1, p: 1e-30, expM: 144, expK: MaxHashes},
+	}
+
+	for _, tc := range tests {
+		if m, k := Estimate(tc.n, tc.p); tc.expM != m || tc.expK != k {
+			t.Errorf("\nexpected (%d, %d)\nreceived (%d, %d)\n", tc.expM, tc.expK, m, k)
+		}
+	}
+
+	for _, p := range []float64{0, 1, math.NaN()} {
+		func() {
+			defer func() {
+				if err, ok := recover().(error); !ok || !errors.Is(err, ErrInvalidParameters) {
+					t.Errorf("\nexpected %v for p = %g\nreceived %v\n", ErrInvalidParameters, p, err)
+				}
+			}()
+
+			Estimate(10, p)
+		}()
+	}
+}
+
+func TestNewPanics(t *testing.T) {
+	for _, mk := range [][2]int{{0, 1}, {1, 0}, {-1, 1}, {1, MaxHashes + 1}} {
+		func() {
+			defer func() {
+				if err, ok := recover().(error); !ok || !errors.Is(err, ErrInvalidParameters) {
+					t.Errorf("\nexpected %v for m = %d, k = %d\nreceived %v\n", ErrInvalidParameters, mk[0], mk[1], err)
+				}
+			}()
+
+			New(mk[0], mk[1])
+		}()
+	}
+}
+
+func TestAddTest(t *testing.T) {
+	const (
+		n = 5000
+		p = 0.01
+	)
+
+	a := NewWithEstimates(n, p)
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			a.AddString(strconv.Itoa(i))
+		} else {
+			a.Add([]byte(strconv.Itoa(i)))
+		}
+	}
+
+	// There are no false negatives, and keys are hashed the same as
+	// strings and bytes.
+	for i := 0; i < n; i++ {
+		if key := strconv.Itoa(i); !a.TestString(key) || !a.Test([]byte(key)) {
+			t.Fatalf("\nexpected %q to be added\n", key)
+		}
+	}
+
+	var fp int
+	for i := n; i < 11*n; i++ {
+		if a.TestString(strconv.Itoa(i)) {
+			fp++
+		}
+	}
+
+	if rate := float64(fp) / (10 * n); 2*p < rate {
+		t.Errorf("\nexpected a false positive rate near %g\nreceived %g\n", p, rate)
+	}
+
+	if rate := a.FalsePositiveRate(); 2*p < rate {
+		t.Errorf("\nexpected an estimated false positive rate near %g\nreceived %g\n", p, rate)
+	}
+
+	if rec := a.Count(); math.Abs(rec-n) > 0.05*n {
+		t.Errorf("\nexpected a count near %d\nreceived %g\n", n, rec)
+	}
+
+	if rec := New(10, 2).Count(); rec != 0 {
+		t.Errorf("\nexpected 0\nreceived %g\n", rec)
+	}
+
+	full := &Filter{k: 3, bits: lmask.Max(10)}
+	if rec := full.Count(); !math.IsInf(rec, 1) {
+		t.Errorf("\nexpected +Inf\nreceived %g\n", rec)
+	}
+}
+
+func TestTestAndAdd(t *testing.T) {
+	a := New(1000, 4)
+	if a.TestAndAddString("x") {
+		t.Errorf("\nexpected %q to be new\n", "x")
+	}
+
+	if !a.TestAndAdd([]byte("x")) || !a.TestString("x") {
+		t.Errorf("\nexpected %q to be added\n", "x")
+	}
+
+	if exp, rec := 4, a.LMask().Count(); rec < 1 || exp < rec {
+		t.Errorf("\nexpected at most %d bits set\nreceived %d\n", exp, rec)
+	}
+}
+
+func TestUnionIntersect(t *testing.T) {
+	var (
+		a = New(2000, 5).AddString("a").AddString("both")
+		b = New(2000, 5).AddString("b").AddString("both")
+	)
+
+	union := a.Copy().Union(b)
+	for _, key := range []string{"a", "b", "both"} {
+		if !union.TestString(key) {
+			t.Errorf("\nexpected %q to be in the union\n", key)
+		}
+	}
+
+	if exp, rec := a.LMask().Or(b.LMask()), union.LMask(); !exp.Equals(rec) {
+		t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+	}
+
+	intersection := a.Copy().Intersect(b)
+	if !intersection.TestString("both") {
+		t.Errorf("\nexpected %q to be in the intersection\n", "both")
+	}
+
+	if exp, rec := a.LMask().And(b.LMask()), intersection.LMask(); !exp.Equals(rec) {
+		t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+	}
+
+	for _, c := range []*Filter{New(2000, 4), New(2001, 5)} {
+		if a.Compatible(c) {
+			t.Errorf("\nexpected filters to be incompatible\n")
+		}
+
+		func() {
+			defer func() {
+				if err, ok := recover().(error); !ok || !errors.Is(err, ErrIncompatible) {
+					t.Errorf("\nexpected %v\nreceived %v\n", ErrIncompatible, err)
+				}
+			}()
+
+			a.Union(c)
+		}()
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	a := New(1001, 3).AddString("a").AddString("b")
+	data, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := new(Filter)
+	if err := b.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if !a.Equals(b) || !b.TestString("a") || b.BitCap() != 1001 || b.Hashes() != 3 {
+		t.Errorf("\nexpected %v\nreceived %v\n", a.LMask(), b.LMask())
+	}
+
+	// The number of hashes is bounded, as adding and testing keys takes
+	// time proportional to it.
+	if err := b.UnmarshalBinary([]byte{binaryVersion, MaxHashes, 1, 8, 0}); err != nil || b.Hashes() != MaxHashes {
+		t.Errorf("\nexpected %d hashes\nreceived %d, %v\n", MaxHashes, b.Hashes(), err)
+	}
+
+	for _, data := range [][]byte{nil, {2, 3}, {binaryVersion}, {binaryVersion, 0}, data[:len(data)-1], {binaryVersion, 3, 1, 0}, {binaryVersion, MaxHashes + 1, 1, 8, 0}, {binaryVersion, 0xff, 0xff, 0xff, 0xff, 0x07, 1, 8, 0}} {
+		if err := new(Filter).UnmarshalBinary(data); !errors.Is(err, ErrInvalidEncoding) {
+			t.Errorf("\nexpected %v\nreceived %v\n", ErrInvalidEncoding, err)
+		}
+	}
+}
+
+func BenchmarkTestAndAdd(b *testing.B) {
+	var (
+		a   = NewWithEstimates(b.N+1, 0.01)
+		key = []byte("key-00000000")
+	)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key[len(key)-1] = byte(i)
+		key[len(key)-2] = byte(i >> 8)
+		a.TestAndAdd(key)
+	}
+}
@@ -0,0 +1,26 @@
+// Package example declares flag types for testing bitmaskgen.
+package example
+
+import "github.com/nathangreene3/bitmask/umask"
+
+//go:generate go run github.com/nathangreene3/bitmask/cmd/bitmaskgen -type=Perm,Feature
+
+// Perm is a set of file permissions.
+type Perm umask.UMask
+
+const (
+	Read Perm = 1 << iota
+	Write
+	Exec
+
+	// ReadWrite is a combination of flags, so is not named.
+	ReadWrite = Read | Write
+)
+
+// Feature is a set of features. Bit 1 is reserved.
+type Feature umask.UMask
+
+const (
+	FeatureBeta    Feature = 1 << 0
+	FeaturePremium Feature = 1 << 2
+)
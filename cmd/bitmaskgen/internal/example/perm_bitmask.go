@@ -0,0 +1,105 @@
+// Code generated by "bitmaskgen -type=Perm,Feature"; DO NOT EDIT.
+
+package example
+
+import "github.com/nathangreene3/bitmask/umask"
+
+// PermAll has every Perm flag set.
+const PermAll Perm = Read | Write | Exec
+
+// _Perm_flags names the bits of a Perm.
+var _Perm_flags = umask.NewFlagSet("Read", "Write", "Exec")
+
+// ParsePerm returns a Perm from flag names separated by "|", such as
+// the text returned by String.
+func ParsePerm(s string) (Perm, error) {
+	a, err := _Perm_flags.Parse(s)
+	if err != nil {
+		return 0, err
+	}
+
+	return Perm(a), nil
+}
+
+// Has determines if each flag set in flag is also set in p.
+func (p Perm) Has(flag Perm) bool {
+	for _, bit := range umask.UMask(flag).Bits() {
+		if !umask.UMask(p).MasksBit(bit) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MarshalText returns the flag names set in p separated by "|".
+func (p Perm) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// String returns the flag names set in p separated by "|". Unnamed bits
+// are formatted as their bit position.
+func (p Perm) String() string {
+	return _Perm_flags.Fmt(umask.UMask(p))
+}
+
+// UnmarshalText decodes flag names separated by "|" into p.
+func (p *Perm) UnmarshalText(text []byte) error {
+	a, err := ParsePerm(string(text))
+	if err != nil {
+		return err
+	}
+
+	*p = a
+	return nil
+}
+
+// FeatureAll has every Feature flag set.
+const FeatureAll Feature = FeatureBeta | FeaturePremium
+
+// _Feature_flags names the bits of a Feature.
+var _Feature_flags = umask.NewFlagSet("FeatureBeta", "", "FeaturePremium")
+
+// ParseFeature returns a Feature from flag names separated by "|", such as
+// the text returned by String.
+func ParseFeature(s string) (Feature, error) {
+	a, err := _Feature_flags.Parse(s)
+	if err != nil {
+		return 0, err
+	}
+
+	return Feature(a), nil
+}
+
+// Has determines if each flag set in flag is also set in p.
+func (p Feature) Has(flag Feature) bool {
+	for _, bit := range umask.UMask(flag).Bits() {
+		if !umask.UMask(p).MasksBit(bit) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MarshalText returns the flag names set in p separated by "|".
+func (p Feature) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// String returns the flag names set in p separated by "|". Unnamed bits
+// are formatted as their bit position.
+func (p Feature) String() string {
+	return _Feature_flags.Fmt(umask.UMask(p))
+}
+
+// UnmarshalText decodes flag names separated by "|" into p.
+func (p *Feature) UnmarshalText(text []byte) error {
+	a, err := ParseFeature(string(text))
+	if err != nil {
+		return err
+	}
+
+	*p = a
+	return nil
+}
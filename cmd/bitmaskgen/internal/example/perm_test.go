@@ -0,0 +1,64 @@
+package example
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nathangreene3/bitmask/umask"
+)
+
+func TestPerm(t *testing.T) {
+	type testCase struct {
+		p   Perm
+		exp string
+	}
+
+	tcs := []testCase{
+		{p: 0, exp: ""},
+		{p: Read, exp: "Read"},
+		{p: Read | Exec, exp: "Read|Exec"},
+		{p: PermAll, exp: "Read|Write|Exec"},
+		{p: Write | 1<<5, exp: "Write|5"},
+	}
+
+	for _, tc := range tcs {
+		if rec := tc.p.String(); tc.exp != rec {
+			t.Errorf("\nexpected %q\nreceived %q\n", tc.exp, rec)
+		}
+
+		if rec, err := ParsePerm(tc.exp); err != nil || tc.p != rec {
+			t.Errorf("\nexpected %d\nreceived %d, %v\n", tc.p, rec, err)
+		}
+	}
+
+	if !PermAll.Has(ReadWrite) || ReadWrite.Has(Exec) || !Exec.Has(0) {
+		t.Error("\nunexpected result from Has\n")
+	}
+
+	if _, err := ParsePerm("Read|Delete"); !errors.Is(err, umask.ErrUnknownFlag) {
+		t.Errorf("\nexpected %v\nreceived %v\n", umask.ErrUnknownFlag, err)
+	}
+}
+
+func TestPermJSON(t *testing.T) {
+	type file struct {
+		Perm    Perm    `json:"perm"`
+		Feature Feature `json:"feature"`
+	}
+
+	exp := file{Perm: Read | Write, Feature: FeatureBeta | FeaturePremium}
+	b, err := json.Marshal(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := `{"perm":"Read|Write","feature":"FeatureBeta|FeaturePremium"}`; s != string(b) {
+		t.Errorf("\nexpected %s\nreceived %s\n", s, b)
+	}
+
+	var rec file
+	if err := json.Unmarshal(b, &rec); err != nil || exp != rec {
+		t.Errorf("\nexpected %v\nreceived %v, %v\n", exp, rec, err)
+	}
+}
@@ -0,0 +1,378 @@
+// Bitmaskgen generates methods for flag types backed by umask.UMask.
+//
+// Given a type declared as
+//
+//	type Perm umask.UMask
+//
+//	const (
+//		Read Perm = 1 << iota
+//		Write
+//		Exec
+//	)
+//
+// running
+//
+//	bitmaskgen -type=Perm
+//
+// in the package directory writes perm_bitmask.go declaring PermAll, a
+// constant having every flag set, the methods Has, String, MarshalText
+// and UnmarshalText, and the function ParsePerm. Flags are formatted as
+// their names separated by "|", such as "Read|Exec", using a
+// umask.FlagSet. Constants having more or less than one bit set, such
+// as combinations of flags, are not named.
+//
+// Typically, bitmaskgen is invoked by go generate:
+//
+//	//go:generate bitmaskgen -type=Perm
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nathangreene3/bitmask/umask"
+)
+
+// umaskPath is the import path of the umask package.
+const umaskPath = "github.com/nathangreene3/bitmask/umask"
+
+var (
+	typeNames  = flag.String("type", "", "comma-separated list of type names; must be set")
+	output     = flag.String("output", "", "output file name; default <dir>/<type>_bitmask.go")
+	trimPrefix = flag.String("trimprefix", "", "trim the prefix from the generated flag names")
+)
+
+// usage prints the usage of bitmaskgen.
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of bitmaskgen:\n")
+	fmt.Fprintf(os.Stderr, "\tbitmaskgen [flags] -type T [directory]\n")
+	fmt.Fprintf(os.Stderr, "Flags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("bitmaskgen: ")
+	flag.Usage = usage
+	flag.Parse()
+	if *typeNames == "" || 1 < flag.NArg() {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	names := strings.Split(*typeNames, ",")
+	src, err := generate(dir, names, *trimPrefix, strings.Join(os.Args[1:], " "))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	name := *output
+	if name == "" {
+		name = filepath.Join(dir, strings.ToLower(names[0])+"_bitmask.go")
+	}
+
+	if err := os.WriteFile(name, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// flagType is a type declared as a umask.UMask and its named bits.
+type flagType struct {
+	name   string
+	consts []string // Constants having exactly one bit set, in order of their bits
+	names  []string // Flag names indexed by bit; empty if the bit is not named
+}
+
+// generate returns formatted source declaring the generated code for
+// the given types in the package in a directory.
+func generate(dir string, typeNames []string, trimPrefix, args string) ([]byte, error) {
+	pkg, files, info, err := load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by \"bitmaskgen %s\"; DO NOT EDIT.\n\n", args)
+	fmt.Fprintf(&b, "package %s\n\n", pkg.Name())
+	fmt.Fprintf(&b, "import %q\n", umaskPath)
+	for _, typeName := range typeNames {
+		t, err := findType(pkg, files, info, typeName, trimPrefix)
+		if err != nil {
+			return nil, err
+		}
+
+		writeType(&b, t)
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+
+	return src, nil
+}
+
+// load parses and type-checks the non-test files of the package in a
+// directory. Imported packages other than umask are not loaded, so type
+// errors are ignored; only the constants of the flag types are needed.
+func load(dir string) (*types.Package, []*ast.File, *types.Info, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		fset  = token.NewFileSet()
+		files = make([]*ast.File, 0, len(names))
+	)
+
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		files = append(files, f)
+	}
+
+	if len(files) == 0 {
+		return nil, nil, nil, fmt.Errorf("no go files in %s", dir)
+	}
+
+	var (
+		conf = types.Config{Importer: importer{}, Error: func(error) {}}
+		info = &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	)
+
+	pkg, _ := conf.Check(files[0].Name.Name, fset, files, info)
+	return pkg, files, info, nil
+}
+
+// findType returns a flag type by name. The type must be declared as a
+// umask.UMask.
+func findType(pkg *types.Package, files []*ast.File, info *types.Info, typeName, trimPrefix string) (flagType, error) {
+	obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return flagType{}, fmt.Errorf("type %s not found", typeName)
+	}
+
+	if !isUMask(files, typeName) {
+		return flagType{}, fmt.Errorf("type %s is not declared as a umask.UMask", typeName)
+	}
+
+	type namedConst struct {
+		name string
+		bit  int
+		pos  token.Pos
+	}
+
+	var consts []namedConst
+	for id, def := range info.Defs {
+		c, ok := def.(*types.Const)
+		if !ok || c.Type() != obj.Type() || c.Parent() != pkg.Scope() {
+			continue
+		}
+
+		v, exact := constant.Uint64Val(constant.ToInt(c.Val()))
+		if !exact || bits.OnesCount64(v) != 1 {
+			continue
+		}
+
+		consts = append(consts, namedConst{name: id.Name, bit: bits.TrailingZeros64(v), pos: id.Pos()})
+	}
+
+	// Earlier declarations take precedence over aliases of the same bit.
+	sort.Slice(consts, func(i, j int) bool {
+		if consts[i].bit != consts[j].bit {
+			return consts[i].bit < consts[j].bit
+		}
+
+		return consts[i].pos < consts[j].pos
+	})
+
+	var (
+		t     = flagType{name: typeName}
+		named = make(map[string]string) // Flag name to constant
+	)
+
+	for _, c := range consts {
+		if c.bit < len(t.names) {
+			continue
+		}
+
+		name := strings.TrimPrefix(c.name, trimPrefix)
+		if name == "" {
+			return flagType{}, fmt.Errorf("flag name of %s is empty after trimming prefix %q", c.name, trimPrefix)
+		}
+
+		if err := umask.CheckFlagName(name); err != nil {
+			return flagType{}, fmt.Errorf("flag name of %s: %w", c.name, err)
+		}
+
+		if other, ok := named[name]; ok {
+			return flagType{}, fmt.Errorf("flag name %q of %s duplicates %s", name, c.name, other)
+		}
+
+		named[name] = c.name
+
+		for len(t.names) < c.bit {
+			t.names = append(t.names, "")
+		}
+
+		t.names = append(t.names, name)
+		t.consts = append(t.consts, c.name)
+	}
+
+	return t, nil
+}
+
+// isUMask determines if a type is declared as umask.UMask.
+func isUMask(files []*ast.File, typeName string) bool {
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.Name.Name != typeName {
+					continue
+				}
+
+				sel, ok := ts.Type.(*ast.SelectorExpr)
+				if !ok || sel.Sel.Name != "UMask" {
+					return false
+				}
+
+				x, ok := sel.X.(*ast.Ident)
+				return ok && importName(f, umaskPath) == x.Name
+			}
+		}
+	}
+
+	return false
+}
+
+// importName returns the name a file refers to an imported package by.
+// If the package is not imported, then the empty string is returned.
+func importName(f *ast.File, path string) string {
+	for _, imp := range f.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == path {
+			if imp.Name != nil {
+				return imp.Name.Name
+			}
+
+			return filepath.Base(path)
+		}
+	}
+
+	return ""
+}
+
+// writeType writes the generated declarations for a flag type.
+func writeType(b *bytes.Buffer, t flagType) {
+	all := "0"
+	if 0 < len(t.consts) {
+		all = strings.Join(t.consts, " | ")
+	}
+
+	names := make([]string, 0, len(t.names))
+	for _, name := range t.names {
+		names = append(names, strconv.Quote(name))
+	}
+
+	fmt.Fprintf(b, `
+// %[1]sAll has every %[1]s flag set.
+const %[1]sAll %[1]s = %[2]s
+
+// _%[1]s_flags names the bits of a %[1]s.
+var _%[1]s_flags = umask.NewFlagSet(%[3]s)
+
+// Parse%[1]s returns a %[1]s from flag names separated by "|", such as
+// the text returned by String.
+func Parse%[1]s(s string) (%[1]s, error) {
+	a, err := _%[1]s_flags.Parse(s)
+	if err != nil {
+		return 0, err
+	}
+
+	return %[1]s(a), nil
+}
+
+// Has determines if each flag set in flag is also set in p.
+func (p %[1]s) Has(flag %[1]s) bool {
+	for _, bit := range umask.UMask(flag).Bits() {
+		if !umask.UMask(p).MasksBit(bit) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MarshalText returns the flag names set in p separated by "|".
+func (p %[1]s) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// String returns the flag names set in p separated by "|". Unnamed bits
+// are formatted as their bit position.
+func (p %[1]s) String() string {
+	return _%[1]s_flags.Fmt(umask.UMask(p))
+}
+
+// UnmarshalText decodes flag names separated by "|" into p.
+func (p *%[1]s) UnmarshalText(text []byte) error {
+	a, err := Parse%[1]s(string(text))
+	if err != nil {
+		return err
+	}
+
+	*p = a
+	return nil
+}
+`, t.name, all, strings.Join(names, ", "))
+}
+
+// importer provides a stub of the umask package declaring UMask so flag
+// types may be type-checked without loading dependencies. Every other
+// package is empty.
+type importer struct{}
+
+// Import returns a stub package.
+func (importer) Import(path string) (*types.Package, error) {
+	pkg := types.NewPackage(path, filepath.Base(path))
+	if path == umaskPath {
+		obj := types.NewTypeName(token.NoPos, pkg, "UMask", nil)
+		types.NewNamed(obj, types.Typ[types.Uint], nil)
+		pkg.Scope().Insert(obj)
+	}
+
+	pkg.MarkComplete()
+	return pkg, nil
+}
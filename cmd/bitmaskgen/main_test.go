@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	exp, err := os.ReadFile(filepath.Join(dir, "perm_bitmask.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := generate(dir, []string{"Perm", "Feature"}, "", "-type=Perm,Feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(exp, rec) {
+		t.Errorf("\nexpected\n%s\nreceived\n%s\n", exp, rec)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	type testCase struct {
+		typeName, trimPrefix, exp string
+	}
+
+	tcs := []testCase{
+		{typeName: "Missing", exp: "not found"},
+		{typeName: "Perm", trimPrefix: "Read", exp: "empty after trimming"},
+	}
+
+	for _, tc := range tcs {
+		_, err := generate(filepath.Join("internal", "example"), []string{tc.typeName}, tc.trimPrefix, "")
+		if err == nil || !strings.Contains(err.Error(), tc.exp) {
+			t.Errorf("\nexpected error containing %q\nreceived %v\n", tc.exp, err)
+		}
+	}
+}
+
+func TestGenerateInvalidNames(t *testing.T) {
+	type testCase struct {
+		consts, trimPrefix, exp string
+	}
+
+	tcs := []testCase{
+		{consts: "Bit0 Bit = 1 << iota; Bit1", trimPrefix: "Bit", exp: "flag name of Bit0: invalid flag name: \"0\" is a number"},
+		{consts: "BitA Bit = 1 << iota; A", trimPrefix: "Bit", exp: "flag name \"A\" of A duplicates BitA"},
+	}
+
+	for _, tc := range tcs {
+		dir := t.TempDir()
+		src := "package p\n\nimport \"" + umaskPath + "\"\n\ntype Bit umask.UMask\n\nconst (\n" + strings.ReplaceAll(tc.consts, "; ", "\n") + "\n)\n"
+		if err := os.WriteFile(filepath.Join(dir, "bit.go"), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := generate(dir, []string{"Bit"}, tc.trimPrefix, "")
+		if err == nil || !strings.Contains(err.Error(), tc.exp) {
+			t.Errorf("\nexpected error containing %q\nreceived %v\n", tc.exp, err)
+		}
+	}
+}
+
+func TestTrimPrefix(t *testing.T) {
+	src, err := generate(filepath.Join("internal", "example"), []string{"Feature"}, "Feature", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := `umask.NewFlagSet("Beta", "", "Premium")`; !strings.Contains(string(src), exp) {
+		t.Errorf("\nexpected source containing %s\nreceived\n%s\n", exp, src)
+	}
+}
@@ -0,0 +1,592 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nathangreene3/bitmask/internal/list"
+	"github.com/nathangreene3/bitmask/lmask"
+	"github.com/nathangreene3/bitmask/umask"
+)
+
+var (
+	// ErrSyntax indicates text is not a valid expression.
+	ErrSyntax = list.ErrSyntax
+
+	// ErrUndefined indicates an expression names a variable not in an
+	// environment.
+	ErrUndefined = errors.New("undefined variable")
+)
+
+// ParseError describes a failure to parse an expression. The position
+// is the byte offset into the text at which parsing failed. The error
+// wraps ErrSyntax.
+type ParseError = list.ParseError
+
+// Expr is a boolean expression over named bitmasks, such as
+// "(premium & ~banned) | staff ^ beta". Variables are identifiers made
+// of letters, digits and underscores not beginning with a digit. The
+// operators, from highest to lowest precedence, are
+//
+//	~x, !x, ^x     Not
+//	x & y, x &^ y  And, AndNot
+//	x ^ y          XOr
+//	x | y          Or
+//
+// Binary operators of equal precedence are left-associative and
+// parentheses group as usual. An And with a negated operand becomes an
+// AndNot, and a negated And, Or or XOr becomes an NAnd, NOr or XNOr, so
+// each is evaluated with a single operation.
+type Expr struct {
+	text string
+	root *node
+	vars []string
+}
+
+// op is an operation of a node.
+type op int
+
+const (
+	opVar op = iota
+	opNot
+	opAnd
+	opAndNot
+	opNAnd
+	opNOr
+	opOr
+	opXNOr
+	opXOr
+)
+
+// negations maps each binary operation having a negation to its
+// negation.
+var negations = map[op]op{opAnd: opNAnd, opNAnd: opAnd, opOr: opNOr, opNOr: opOr, opXOr: opXNOr, opXNOr: opXOr}
+
+// node is a node of an expression tree. A variable has a slot indexing
+// the sorted variables of the expression.
+type node struct {
+	op   op
+	slot int
+	x, y *node
+}
+
+// --------------------------------------------------------------------
+// Constructors
+// --------------------------------------------------------------------
+
+// MustParse returns a parsed expression. If the text is not a valid
+// expression, then MustParse panics.
+func MustParse(s string) *Expr {
+	e, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return e
+}
+
+// Parse returns a parsed expression. A *ParseError is returned if the
+// text is not a valid expression.
+func Parse(s string) (*Expr, error) {
+	p := parser{text: s, slots: make(map[string]int)}
+	p.next()
+	root := p.parseOr()
+	if p.err == nil && p.tok != tokEOF {
+		p.fail("unexpected %s", p.describe())
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	// Renumber the slots in the order of the sorted variables.
+	e := &Expr{text: s, root: root, vars: make([]string, 0, len(p.slots))}
+	for name := range p.slots {
+		e.vars = append(e.vars, name)
+	}
+
+	sort.Strings(e.vars)
+	order := make([]int, len(e.vars))
+	for i, name := range e.vars {
+		order[p.slots[name]] = i
+	}
+
+	root.renumber(order)
+	return e, nil
+}
+
+// --------------------------------------------------------------------
+// Expression functionality
+// --------------------------------------------------------------------
+
+// Compile returns a program evaluating an expression over the bitmasks
+// of an environment. Each variable must be defined and all must have the
+// same bit capacity, so evaluation needs no further checks. An error
+// wrapping ErrUndefined or lmask.ErrUnequalBitCaps is returned
+// otherwise.
+func (e *Expr) Compile(env map[string]*lmask.LMask) (*Program, error) {
+	p := &Program{root: e.root, masks: make([]*lmask.LMask, len(e.vars)), scratch: make([]*lmask.LMask, e.root.depth())}
+	for i := range p.scratch {
+		p.scratch[i] = new(lmask.LMask)
+	}
+
+	for i, name := range e.vars {
+		m, ok := env[name]
+		if !ok || m == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUndefined, name)
+		}
+
+		if 0 < i && m.BitCap() != p.masks[0].BitCap() {
+			return nil, fmt.Errorf("%w: %s has %d bits and %s has %d", lmask.ErrUnequalBitCaps, e.vars[0], p.masks[0].BitCap(), name, m.BitCap())
+		}
+
+		p.masks[i] = m
+	}
+
+	return p, nil
+}
+
+// EvalLMask returns a new bitmask evaluating an expression over the
+// bitmasks of an environment. It is Compile followed by Eval.
+func (e *Expr) EvalLMask(env map[string]*lmask.LMask) (*lmask.LMask, error) {
+	p, err := e.Compile(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Eval(), nil
+}
+
+// EvalUMask returns an expression evaluated over the bitmasks of an
+// environment. An error wrapping ErrUndefined is returned if a variable
+// is not defined.
+func (e *Expr) EvalUMask(env map[string]umask.UMask) (umask.UMask, error) {
+	masks := make([]umask.UMask, len(e.vars))
+	for i, name := range e.vars {
+		m, ok := env[name]
+		if !ok {
+			return 0, fmt.Errorf("%w: %s", ErrUndefined, name)
+		}
+
+		masks[i] = m
+	}
+
+	return e.root.evalUMask(masks), nil
+}
+
+// String returns an expression with minimal parentheses. Parsing the
+// result returns an equivalent expression.
+func (e *Expr) String() string {
+	var b strings.Builder
+	e.root.format(&b, e.vars, 0)
+	return b.String()
+}
+
+// Vars returns the names of the variables in increasing order.
+func (e *Expr) Vars() []string {
+	return append(make([]string, 0, len(e.vars)), e.vars...)
+}
+
+// --------------------------------------------------------------------
+// Program functionality
+// --------------------------------------------------------------------
+
+// Program is an expression compiled over the bitmasks of an environment.
+// The bitmasks may be modified between evaluations, but their bit
+// capacities must not change. A program reuses its scratch bitmasks on
+// every evaluation, so it is not safe for concurrent use.
+type Program struct {
+	root    *node
+	masks   []*lmask.LMask
+	scratch []*lmask.LMask // Right operands, indexed by depth
+}
+
+// BitCap returns the bit capacity of the bitmasks evaluated over.
+func (p *Program) BitCap() int {
+	return p.masks[0].BitCap()
+}
+
+// Eval returns a new bitmask evaluating the expression. No bitmask of
+// the environment is modified.
+func (p *Program) Eval() *lmask.LMask {
+	return p.EvalTo(new(lmask.LMask))
+}
+
+// EvalTo sets a bitmask to the evaluated expression, reusing its words.
+// The bitmask must not be in the environment.
+func (p *Program) EvalTo(dst *lmask.LMask) *lmask.LMask {
+	if m := p.operand(p.root, dst, 0); m != dst {
+		dst.CopyOf(m)
+	}
+
+	return dst
+}
+
+// operand returns a node evaluated. A variable is its bitmask and any
+// other node is evaluated into a scratch bitmask. The right operand of a
+// binary node at a depth is evaluated into the program's scratch bitmask
+// of that depth, as the left operand is already in the given one.
+func (p *Program) operand(n *node, scratch *lmask.LMask, depth int) *lmask.LMask {
+	if n.op == opVar {
+		return p.masks[n.slot]
+	}
+
+	if n.op == opNot {
+		return scratch.NotOf(p.operand(n.x, scratch, depth))
+	}
+
+	var (
+		x = p.operand(n.x, scratch, depth)
+		y = p.operand(n.y, p.scratch[depth], depth+1)
+	)
+
+	switch n.op {
+	case opAnd:
+		return scratch.AndOf(x, y)
+	case opAndNot:
+		return scratch.AndNotOf(x, y)
+	case opNAnd:
+		return scratch.NAndOf(x, y)
+	case opNOr:
+		return scratch.NOrOf(x, y)
+	case opOr:
+		return scratch.OrOf(x, y)
+	case opXNOr:
+		return scratch.XNOrOf(x, y)
+	default:
+		return scratch.XOrOf(x, y)
+	}
+}
+
+// --------------------------------------------------------------------
+// Tree helpers
+// --------------------------------------------------------------------
+
+// binary returns a node applying a binary operation, folding a negated
+// right operand of an And or AndNot.
+func binary(o op, x, y *node) *node {
+	switch {
+	case o == opAnd && y.op == opNot:
+		return &node{op: opAndNot, x: x, y: y.x}
+	case o == opAndNot && y.op == opNot:
+		return &node{op: opAnd, x: x, y: y.x}
+	}
+
+	return &node{op: o, x: x, y: y}
+}
+
+// not returns a node negating another, folding double negation and
+// negated And, Or and XOr.
+func not(x *node) *node {
+	switch o, ok := negations[x.op]; {
+	case x.op == opNot:
+		return x.x
+	case ok:
+		return &node{op: o, x: x.x, y: x.y}
+	}
+
+	return &node{op: opNot, x: x}
+}
+
+// depth returns the number of scratch bitmasks evaluating a node needs
+// besides the one it is evaluated into.
+func (n *node) depth() int {
+	switch n.op {
+	case opVar:
+		return 0
+	case opNot:
+		return n.x.depth()
+	default:
+		return max(n.x.depth(), 1+n.y.depth())
+	}
+}
+
+// evalUMask returns a node evaluated over a list of bitmasks indexed by
+// slot.
+func (n *node) evalUMask(masks []umask.UMask) umask.UMask {
+	if n.op == opVar {
+		return masks[n.slot]
+	}
+
+	x := n.x.evalUMask(masks)
+	if n.op == opNot {
+		return x.Not()
+	}
+
+	y := n.y.evalUMask(masks)
+	switch n.op {
+	case opAnd:
+		return x.And(y)
+	case opAndNot:
+		return x.AndNot(y)
+	case opNAnd:
+		return x.NAnd(y)
+	case opNOr:
+		return x.NOr(y)
+	case opOr:
+		return x.Or(y)
+	case opXNOr:
+		return x.XNOr(y)
+	default:
+		return x.XOr(y)
+	}
+}
+
+// format writes a node, parenthesized if its precedence is less than a
+// given precedence.
+func (n *node) format(b *strings.Builder, vars []string, prec int) {
+	var (
+		symbol string
+		p      int
+	)
+
+	switch n.op {
+	case opVar:
+		b.WriteString(vars[n.slot])
+		return
+	case opNot:
+		b.WriteByte('~')
+		n.x.format(b, vars, precNot)
+		return
+	case opNAnd, opNOr, opXNOr:
+		// Written as a negated And, Or or XOr.
+		b.WriteString("~(")
+		(&node{op: negations[n.op], x: n.x, y: n.y}).format(b, vars, 0)
+		b.WriteByte(')')
+		return
+	case opAnd:
+		symbol, p = " & ", precAnd
+	case opAndNot:
+		symbol, p = " &^ ", precAnd
+	case opOr:
+		symbol, p = " | ", precOr
+	default:
+		symbol, p = " ^ ", precXOr
+	}
+
+	if p < prec {
+		b.WriteByte('(')
+	}
+
+	n.x.format(b, vars, p)
+	b.WriteString(symbol)
+	n.y.format(b, vars, p+1)
+	if p < prec {
+		b.WriteByte(')')
+	}
+}
+
+// renumber replaces the slot of each variable.
+func (n *node) renumber(order []int) {
+	switch n.op {
+	case opVar:
+		n.slot = order[n.slot]
+	case opNot:
+		n.x.renumber(order)
+	default:
+		n.x.renumber(order)
+		n.y.renumber(order)
+	}
+}
+
+// --------------------------------------------------------------------
+// Parser helpers
+// --------------------------------------------------------------------
+
+// Operator precedences used when formatting.
+const (
+	precOr = iota + 1
+	precXOr
+	precAnd
+	precNot
+)
+
+// token is a kind of token.
+type token int
+
+const (
+	tokEOF token = iota
+	tokIdent
+	tokNot
+	tokAnd
+	tokAndNot
+	tokXOr
+	tokOr
+	tokLParen
+	tokRParen
+	tokInvalid
+)
+
+// parser is a recursive descent parser of expressions. Each parse
+// method returns nil once an error has occurred.
+type parser struct {
+	text     string
+	pos, end int // The bounds of the current token
+	tok      token
+	slots    map[string]int
+	err      error
+}
+
+// describe returns a description of the current token.
+func (p *parser) describe() string {
+	if p.tok == tokEOF {
+		return "end of expression"
+	}
+
+	return fmt.Sprintf("%q", p.text[p.pos:p.end])
+}
+
+// fail records a syntax error at the current token.
+func (p *parser) fail(format string, args ...any) {
+	if p.err == nil {
+		p.err = &ParseError{Text: p.text, Pos: p.pos, Err: fmt.Errorf("%w: "+format, append([]any{ErrSyntax}, args...)...)}
+	}
+}
+
+// next advances to the next token.
+func (p *parser) next() {
+	p.pos = p.end
+	for p.pos < len(p.text) {
+		r, n := utf8.DecodeRuneInString(p.text[p.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+
+		p.pos += n
+	}
+
+	p.end = p.pos
+	if p.pos == len(p.text) {
+		p.tok = tokEOF
+		return
+	}
+
+	r, n := utf8.DecodeRuneInString(p.text[p.pos:])
+	p.end += n
+	switch r {
+	case '~', '!':
+		p.tok = tokNot
+	case '&':
+		p.tok = tokAnd
+		if p.end < len(p.text) && p.text[p.end] == '^' {
+			p.tok, p.end = tokAndNot, p.end+1
+		}
+	case '^':
+		p.tok = tokXOr
+	case '|':
+		p.tok = tokOr
+	case '(':
+		p.tok = tokLParen
+	case ')':
+		p.tok = tokRParen
+	default:
+		if r != '_' && !unicode.IsLetter(r) {
+			p.tok = tokInvalid
+			return
+		}
+
+		p.tok = tokIdent
+		for p.end < len(p.text) {
+			r, n := utf8.DecodeRuneInString(p.text[p.end:])
+			if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+
+			p.end += n
+		}
+	}
+}
+
+// parseOr parses operands separated by |.
+func (p *parser) parseOr() *node {
+	x := p.parseXOr()
+	for p.err == nil && p.tok == tokOr {
+		p.next()
+		if y := p.parseXOr(); y != nil {
+			x = binary(opOr, x, y)
+		}
+	}
+
+	return p.result(x)
+}
+
+// parseXOr parses operands separated by ^.
+func (p *parser) parseXOr() *node {
+	x := p.parseAnd()
+	for p.err == nil && p.tok == tokXOr {
+		p.next()
+		if y := p.parseAnd(); y != nil {
+			x = binary(opXOr, x, y)
+		}
+	}
+
+	return p.result(x)
+}
+
+// parseAnd parses operands separated by & or &^.
+func (p *parser) parseAnd() *node {
+	x := p.parseUnary()
+	for p.err == nil && (p.tok == tokAnd || p.tok == tokAndNot) {
+		o := opAnd
+		if p.tok == tokAndNot {
+			o = opAndNot
+		}
+
+		p.next()
+		if y := p.parseUnary(); y != nil {
+			x = binary(o, x, y)
+		}
+	}
+
+	return p.result(x)
+}
+
+// parseUnary parses a negated operand, a variable or a parenthesized
+// expression.
+func (p *parser) parseUnary() *node {
+	switch p.tok {
+	case tokNot, tokXOr:
+		p.next()
+		x := p.parseUnary()
+		if p.err != nil {
+			return nil
+		}
+
+		return not(x)
+	case tokIdent:
+		name := p.text[p.pos:p.end]
+		slot, ok := p.slots[name]
+		if !ok {
+			slot = len(p.slots)
+			p.slots[name] = slot
+		}
+
+		p.next()
+		return &node{op: opVar, slot: slot}
+	case tokLParen:
+		p.next()
+		x := p.parseOr()
+		if p.err == nil && p.tok != tokRParen {
+			p.fail("expected \")\", found %s", p.describe())
+		}
+
+		p.next()
+		return p.result(x)
+	default:
+		p.fail("expected operand, found %s", p.describe())
+		return nil
+	}
+}
+
+// result returns a node, or nil if an error has occurred.
+func (p *parser) result(x *node) *node {
+	if p.err != nil {
+		return nil
+	}
+
+	return x
+}
@@ -0,0 +1,222 @@
+package expr
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/nathangreene3/bitmask/lmask"
+	"github.com/nathangreene3/bitmask/umask"
+)
+
+func TestParse(t *testing.T) {
+	type testCase struct {
+		s    string
+		exp  string
+		vars []string
+	}
+
+	tests := []testCase{
+		{s: "a", exp: "a", vars: []string{"a"}},
+		{s: " (premium & ~banned) | staff ^ beta ", exp: "premium &^ banned | staff ^ beta", vars: []string{"banned", "beta", "premium", "staff"}},
+		{s: "a | b & c", exp: "a | b & c", vars: []string{"a", "b", "c"}},
+		{s: "(a | b) & c", exp: "(a | b) & c", vars: []string{"a", "b", "c"}},
+		{s: "a ^ b | c ^ d", exp: "a ^ b | c ^ d", vars: []string{"a", "b", "c", "d"}},
+		{s: "a ^ (b ^ c)", exp: "a ^ (b ^ c)", vars: []string{"a", "b", "c"}},
+		{s: "a &^ b &^ c", exp: "a &^ b &^ c", vars: []string{"a", "b", "c"}},
+		{s: "a &^ (b | c)", exp: "a &^ (b | c)", vars: []string{"a", "b", "c"}},
+		{s: "a & ~b", exp: "a &^ b", vars: []string{"a", "b"}},
+		{s: "a &^ ~b", exp: "a & b", vars: []string{"a", "b"}},
+		{s: "~~a", exp: "a", vars: []string{"a"}},
+		{s: "!(a & b)", exp: "~(a & b)", vars: []string{"a", "b"}},
+		{s: "^(a | b) & c", exp: "~(a | b) & c", vars: []string{"a", "b", "c"}},
+		{s: "~(a ^ b)", exp: "~(a ^ b)", vars: []string{"a", "b"}},
+		{s: "~~(a ^ b)", exp: "a ^ b", vars: []string{"a", "b"}},
+		{s: "~(a &^ b)", exp: "~(a &^ b)", vars: []string{"a", "b"}},
+		{s: "x_1 | _y | été", exp: "x_1 | _y | été", vars: []string{"_y", "x_1", "été"}},
+		{s: "a&a|a", exp: "a & a | a", vars: []string{"a"}},
+	}
+
+	for _, tc := range tests {
+		e, err := Parse(tc.s)
+		if err != nil {
+			t.Fatalf("\nexpected no error\nreceived %v\n", err)
+		}
+
+		if rec := e.String(); tc.exp != rec {
+			t.Errorf("\nexpected %q\nreceived %q\n", tc.exp, rec)
+		}
+
+		if rec := MustParse(e.String()).String(); tc.exp != rec {
+			t.Errorf("\nexpected %q\nreceived %q\n", tc.exp, rec)
+		}
+
+		if rec := e.Vars(); !slices.Equal(tc.vars, rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", tc.vars, rec)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	type testCase struct {
+		s   string
+		pos int
+	}
+
+	tests := []testCase{
+		{s: "", pos: 0},
+		{s: "   ", pos: 3},
+		{s: "a &", pos: 3},
+		{s: "a b", pos: 2},
+		{s: "(a | b", pos: 6},
+		{s: "a | b)", pos: 5},
+		{s: "a | 1b", pos: 4},
+		{s: "a + b", pos: 2},
+		{s: "~", pos: 1},
+		{s: "a & (| b)", pos: 5},
+		{s: "a && b", pos: 3},
+	}
+
+	for _, tc := range tests {
+		_, err := Parse(tc.s)
+		var perr *ParseError
+		if !errors.As(err, &perr) || !errors.Is(err, ErrSyntax) {
+			t.Errorf("\nexpected a *ParseError for %q\nreceived %v\n", tc.s, err)
+			continue
+		}
+
+		if tc.pos != perr.Pos {
+			t.Errorf("\nexpected position %d for %q\nreceived %d (%v)\n", tc.pos, tc.s, perr.Pos, err)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	var (
+		r      = rand.New(rand.NewSource(0))
+		bitCap = 3*lmask.WordBitCap + 5
+		names  = []string{"a", "b", "c", "d"}
+		lenv   = make(map[string]*lmask.LMask)
+		uenv   = make(map[string]umask.UMask)
+	)
+
+	for _, name := range names {
+		lenv[name] = lmask.Zero(bitCap)
+		for bit := 0; bit < bitCap; bit++ {
+			if r.Intn(2) == 0 {
+				lenv[name].SetBit(bit)
+			}
+		}
+
+		uenv[name] = umask.UMask(lenv[name].Words()[0])
+	}
+
+	// Each expression is checked bit by bit against its truth table.
+	type testCase struct {
+		s     string
+		truth func(a, b, c, d bool) bool
+	}
+
+	tests := []testCase{
+		{s: "a", truth: func(a, b, c, d bool) bool { return a }},
+		{s: "~a", truth: func(a, b, c, d bool) bool { return !a }},
+		{s: "a & b", truth: func(a, b, c, d bool) bool { return a && b }},
+		{s: "a & ~b", truth: func(a, b, c, d bool) bool { return a && !b }},
+		{s: "a &^ b", truth: func(a, b, c, d bool) bool { return a && !b }},
+		{s: "~(a & b)", truth: func(a, b, c, d bool) bool { return !(a && b) }},
+		{s: "~(a | b)", truth: func(a, b, c, d bool) bool { return !(a || b) }},
+		{s: "~(a ^ b)", truth: func(a, b, c, d bool) bool { return a == b }},
+		{s: "(a & ~b) | c ^ d", truth: func(a, b, c, d bool) bool { return a && !b || c != d }},
+		{s: "a | b & c", truth: func(a, b, c, d bool) bool { return a || b && c }},
+		{s: "~(a | ~(b & c)) ^ (d &^ ~a)", truth: func(a, b, c, d bool) bool { return !(a || !(b && c)) != (d && a) }},
+		{s: "a & a ^ a", truth: func(a, b, c, d bool) bool { return false }},
+		{s: "a ^ (b | (c & ~(d ^ a)))", truth: func(a, b, c, d bool) bool { return a != (b || c && d == a) }},
+	}
+
+	for _, tc := range tests {
+		e := MustParse(tc.s)
+		p, err := e.Compile(lenv)
+		if err != nil {
+			t.Fatalf("\nexpected no error\nreceived %v\n", err)
+		}
+
+		exp := lmask.Zero(bitCap)
+		for bit := 0; bit < bitCap; bit++ {
+			if tc.truth(lenv["a"].MasksBit(bit), lenv["b"].MasksBit(bit), lenv["c"].MasksBit(bit), lenv["d"].MasksBit(bit)) {
+				exp.SetBit(bit)
+			}
+		}
+
+		// Scratch bitmasks are reused by each evaluation.
+		for i := 0; i < 2; i++ {
+			if rec := p.Eval(); !exp.Equals(rec) {
+				t.Errorf("\nexpected %v\nreceived %v\nfor %q\n", exp, rec, tc.s)
+			}
+		}
+
+		if rec, err := e.EvalLMask(lenv); err != nil || !exp.Equals(rec) {
+			t.Errorf("\nexpected %v\nreceived %v (%v)\nfor %q\n", exp, rec, err, tc.s)
+		}
+
+		if exp, rec := umask.UMask(exp.Words()[0]), func() umask.UMask { m, _ := e.EvalUMask(uenv); return m }(); exp != rec {
+			t.Errorf("\nexpected %v\nreceived %v\nfor %q\n", exp, rec, tc.s)
+		}
+	}
+
+	// Evaluation reads the environment as it is and never modifies it.
+	p, _ := MustParse("a | b").Compile(lenv)
+	a := lenv["a"].Copy()
+	lenv["b"].SetBit(0)
+	if rec := p.Eval(); !rec.MasksBit(0) || !a.Equals(lenv["a"]) {
+		t.Errorf("\nexpected bit 0 to be set\nreceived %v\n", rec)
+	}
+
+	if rec := p.EvalTo(lmask.Max(7)); rec.BitCap() != bitCap || rec.BitCap() != p.BitCap() {
+		t.Errorf("\nexpected %d\nreceived %d\n", bitCap, rec.BitCap())
+	}
+
+	// Once evaluated, a program allocates nothing more.
+	var (
+		dst  = lmask.Zero(bitCap)
+		q, _ = MustParse("~(a | ~(b & c)) ^ (d &^ ~a)").Compile(lenv)
+	)
+
+	q.EvalTo(dst)
+	if rec := testing.AllocsPerRun(10, func() { q.EvalTo(dst) }); rec != 0 {
+		t.Errorf("\nexpected %d allocations\nreceived %v\n", 0, rec)
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	env := map[string]*lmask.LMask{"a": lmask.Zero(8), "b": lmask.Zero(8), "c": lmask.Zero(9)}
+	if _, err := MustParse("a | x").Compile(env); !errors.Is(err, ErrUndefined) {
+		t.Errorf("\nexpected %v\nreceived %v\n", ErrUndefined, err)
+	}
+
+	if _, err := MustParse("a | b & c").Compile(env); !errors.Is(err, lmask.ErrUnequalBitCaps) {
+		t.Errorf("\nexpected %v\nreceived %v\n", lmask.ErrUnequalBitCaps, err)
+	}
+
+	if _, err := MustParse("a | b").EvalLMask(env); err != nil {
+		t.Errorf("\nexpected no error\nreceived %v\n", err)
+	}
+
+	if _, err := MustParse("a ^ y").EvalUMask(map[string]umask.UMask{"a": 1}); !errors.Is(err, ErrUndefined) {
+		t.Errorf("\nexpected %v\nreceived %v\n", ErrUndefined, err)
+	}
+}
+
+func BenchmarkEval(b *testing.B) {
+	var (
+		bitCap = 1 << 16
+		env    = map[string]*lmask.LMask{"a": lmask.Max(bitCap), "b": lmask.Zero(bitCap), "c": lmask.One(bitCap), "d": lmask.Max(bitCap)}
+		p, _   = MustParse("(a & ~b) | c ^ d").Compile(env)
+		dst    = lmask.Zero(bitCap)
+	)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.EvalTo(dst)
+	}
+}
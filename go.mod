@@ -1,3 +1,3 @@
 module github.com/nathangreene3/bitmask
 
-go 1.16
+go 1.23
@@ -0,0 +1,9 @@
+// Package bounds declares the error the bitmask packages share for bits
+// and ranges of bits not within a bit capacity.
+package bounds
+
+import "errors"
+
+// ErrBitOutOfRange indicates a bit is not on range [0, bitCap). It is
+// shared by the bitmask packages so errors.Is matches across them.
+var ErrBitOutOfRange = errors.New("bit out of range")
@@ -0,0 +1,163 @@
+// Package list parses and formats bitmasks as lists of bit ranges, such
+// as "0-3,8,10-15", and as comma-separated 32-bit hex groups, such as
+// "00000000,0000ff0f". These are the formats Linux uses for cpu lists
+// and cpu masks.
+package list
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrSyntax indicates text is not a valid list or hex mask.
+var ErrSyntax = errors.New("invalid syntax")
+
+// ParseError describes a failure to parse text at a position.
+type ParseError struct {
+	Text string // The text being parsed
+	Pos  int    // The byte offset into the text
+	Err  error  // The reason parsing failed
+}
+
+// Error returns a description of the failure.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parsing %q at position %d: %v", e.Text, e.Pos, e.Err)
+}
+
+// Unwrap returns the reason parsing failed.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// AppendRange appends a range of bits [lo, hi] to a list.
+func AppendRange(b []byte, lo, hi int) []byte {
+	if 0 < len(b) {
+		b = append(b, ',')
+	}
+
+	b = strconv.AppendInt(b, int64(lo), 10)
+	if lo < hi {
+		b = append(b, '-')
+		b = strconv.AppendInt(b, int64(hi), 10)
+	}
+
+	return b
+}
+
+// Parse calls set for each range of bits [lo, hi] in a list such as
+// "0-3,8,10-15". Surrounding whitespace is ignored and the empty list
+// has no ranges. Any error returned by set is wrapped in a ParseError
+// at the position of the range.
+func Parse(s string, set func(lo, hi int) error) error {
+	t := strings.TrimSpace(s)
+	if t == "" {
+		return nil
+	}
+
+	pos := strings.Index(s, t)
+	for _, item := range strings.Split(t, ",") {
+		lo, hi, err := parseRange(item)
+		if err != nil {
+			return &ParseError{Text: s, Pos: pos + err.(*ParseError).Pos, Err: ErrSyntax}
+		}
+
+		if err := set(lo, hi); err != nil {
+			return &ParseError{Text: s, Pos: pos, Err: err}
+		}
+
+		pos += len(item) + 1
+	}
+
+	return nil
+}
+
+// parseRange returns the bits of a range "lo-hi" or a single bit "n".
+// The returned error is a ParseError holding only the position.
+func parseRange(s string) (int, int, error) {
+	if s == "" {
+		return 0, 0, &ParseError{}
+	}
+
+	loText, hiText, isRange := strings.Cut(s, "-")
+	lo, err := parseBit(loText)
+	if err != nil {
+		return 0, 0, &ParseError{}
+	}
+
+	if !isRange {
+		return lo, lo, nil
+	}
+
+	hi, err := parseBit(hiText)
+	if err != nil || hi < lo {
+		return 0, 0, &ParseError{Pos: len(loText) + 1}
+	}
+
+	return lo, hi, nil
+}
+
+// parseBit returns a bit written as a non-negative decimal integer.
+func parseBit(s string) (int, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || '9' < s[i] {
+			return 0, ErrSyntax
+		}
+	}
+
+	return strconv.Atoi(s)
+}
+
+// FormatHex returns n 32-bit groups as hex separated by commas, with
+// the most significant group first. The group function returns the ith
+// group, counting from the least significant.
+func FormatHex(n int, group func(i int) uint32) string {
+	var b []byte
+	for i := n - 1; 0 <= i; i-- {
+		if len(b) != 0 {
+			b = append(b, ',')
+		}
+
+		b = fmt.Appendf(b, "%08x", group(i))
+	}
+
+	return string(b)
+}
+
+// ParseHex calls set for each 32-bit group of a hex mask such as
+// "00000000,0000ff0f", counting from the least significant group. Each
+// group has one to eight hex digits. Surrounding whitespace is ignored.
+// Any error returned by set is wrapped in a ParseError at the position
+// of the group.
+func ParseHex(s string, set func(i int, group uint32) error) error {
+	t := strings.TrimSpace(s)
+	pos := strings.Index(s, t) + len(t)
+	groups := strings.Split(t, ",")
+	for i := len(groups) - 1; 0 <= i; i-- {
+		pos -= len(groups[i])
+		if len(groups[i]) == 0 || 8 < len(groups[i]) {
+			return &ParseError{Text: s, Pos: pos, Err: ErrSyntax}
+		}
+
+		for j := 0; j < len(groups[i]); j++ {
+			if !isHex(groups[i][j]) {
+				return &ParseError{Text: s, Pos: pos + j, Err: ErrSyntax}
+			}
+		}
+
+		w, _ := strconv.ParseUint(groups[i], 16, 32)
+		if err := set(len(groups)-1-i, uint32(w)); err != nil {
+			return &ParseError{Text: s, Pos: pos, Err: err}
+		}
+
+		pos--
+	}
+
+	return nil
+}
+
+// isHex determines if a byte is a hex digit.
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,95 @@
+package lmask
+
+import "math/bits"
+
+// Add sets a to the sum of a and b modulo 2^bitCap and returns whether
+// the sum overflowed. The bit capacities of a and b must be equal.
+func (a *LMask) Add(b *LMask) bool {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	var c uint
+	for i := 0; i < len(a.words); i++ {
+		a.words[i], c = bits.Add(a.words[i], b.words[i], c)
+	}
+
+	return a.overflowed(c)
+}
+
+// Cmp compares a and b as unsigned integers and returns -1 if a < b, 0 if
+// a = b, and 1 if a > b. The bit capacities of a and b must be equal.
+func (a *LMask) Cmp(b *LMask) int {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	for i := len(a.words) - 1; 0 <= i; i-- {
+		switch {
+		case a.words[i] < b.words[i]:
+			return -1
+		case b.words[i] < a.words[i]:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// Dec subtracts one from a modulo 2^bitCap and returns whether the
+// difference underflowed. That is, Dec returns true only if a was zero.
+func (a *LMask) Dec() bool {
+	c := uint(1)
+	for i := 0; i < len(a.words) && c != 0; i++ {
+		a.words[i], c = bits.Sub(a.words[i], 0, c)
+	}
+
+	return a.overflowed(c)
+}
+
+// Inc adds one to a modulo 2^bitCap and returns whether the sum
+// overflowed. That is, Inc returns true only if every bit was set.
+func (a *LMask) Inc() bool {
+	c := uint(1)
+	for i := 0; i < len(a.words) && c != 0; i++ {
+		a.words[i], c = bits.Add(a.words[i], 0, c)
+	}
+
+	return a.overflowed(c)
+}
+
+// Neg sets a to its two's complement 2^bitCap - a modulo 2^bitCap.
+func (a *LMask) Neg() *LMask {
+	a.Not().Inc()
+	return a
+}
+
+// Sub sets a to the difference of a and b modulo 2^bitCap and returns
+// whether the difference underflowed. That is, Sub returns true only if
+// a < b. The bit capacities of a and b must be equal.
+func (a *LMask) Sub(b *LMask) bool {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	var c uint
+	for i := 0; i < len(a.words); i++ {
+		a.words[i], c = bits.Sub(a.words[i], b.words[i], c)
+	}
+
+	return a.overflowed(c)
+}
+
+// overflowed trims the result of an addition or subtraction and returns
+// whether it carried or borrowed beyond the bit capacity, given the
+// carry or borrow out of the last word. When the last word is partially
+// used, its high bits are unset before the operation, so a carry or
+// borrow beyond the bit capacity is left in the first unused bit.
+func (a *LMask) overflowed(c uint) bool {
+	if r := a.bitCap - a.bitCap/WordBitCap*WordBitCap; 0 < r {
+		c = a.words[len(a.words)-1] >> r & 1
+	}
+
+	a.trim()
+	return c != 0
+}
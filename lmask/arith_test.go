@@ -0,0 +1,99 @@
+package lmask
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestArith(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, bitCap := range []int{1, 7, WordBitCap - 1, WordBitCap, WordBitCap + 1, 3*WordBitCap + 5} {
+		var (
+			mod   = new(big.Int).Lsh(big.NewInt(1), uint(bitCap))
+			masks = []*LMask{Zero(bitCap), One(bitCap), Max(bitCap), randomMask(r, bitCap), randomMask(r, bitCap)}
+		)
+
+		for _, a := range masks {
+			for _, b := range masks {
+				testArith(t, mod, a, b)
+			}
+
+			testArith(t, mod, a, nil)
+		}
+	}
+
+	// Every integer is zero modulo 2^0.
+	if a := Zero(0); !a.Inc() || !a.Dec() || a.Add(Zero(0)) || a.Sub(Zero(0)) {
+		t.Errorf("\nexpected overflow only on inc and dec of bit cap 0\n")
+	}
+}
+
+// testArith tests the arithmetic of a and b against the same arithmetic
+// on big integers modulo mod. If b is nil, then Inc, Dec and Neg on a
+// are tested instead.
+func testArith(t *testing.T, mod *big.Int, a, b *LMask) {
+	x := a.BigInt()
+	if b == nil {
+		for _, tc := range []struct {
+			name string
+			f    func(*LMask) bool
+			d    int64
+		}{
+			{name: "inc", f: (*LMask).Inc, d: 1},
+			{name: "dec", f: (*LMask).Dec, d: -1},
+		} {
+			exp := new(big.Int).Add(x, big.NewInt(tc.d))
+			expOver := exp.Sign() < 0 || mod.Cmp(exp) <= 0
+			exp.Mod(exp, mod)
+
+			rec := a.Copy()
+			if recOver := tc.f(rec); expOver != recOver || exp.Cmp(rec.BigInt()) != 0 {
+				t.Errorf("\n%s %v (bit cap %d)\nexpected %v, %t\nreceived %v, %t\n", tc.name, a, a.BitCap(), exp, expOver, rec, recOver)
+			}
+		}
+
+		exp := new(big.Int).Neg(x)
+		exp.Mod(exp, mod)
+		if rec := a.Copy().Neg(); exp.Cmp(rec.BigInt()) != 0 {
+			t.Errorf("\nneg %v (bit cap %d)\nexpected %v\nreceived %v\n", a, a.BitCap(), exp, rec)
+		}
+
+		return
+	}
+
+	y := b.BigInt()
+	exp := new(big.Int).Add(x, y)
+	expOver := mod.Cmp(exp) <= 0
+	exp.Mod(exp, mod)
+
+	rec := a.Copy()
+	if recOver := rec.Add(b); expOver != recOver || exp.Cmp(rec.BigInt()) != 0 {
+		t.Errorf("\n%v + %v (bit cap %d)\nexpected %v, %t\nreceived %v, %t\n", a, b, a.BitCap(), exp, expOver, rec, recOver)
+	}
+
+	exp = new(big.Int).Sub(x, y)
+	expOver = exp.Sign() < 0
+	exp.Mod(exp, mod)
+
+	rec = a.Copy()
+	if recOver := rec.Sub(b); expOver != recOver || exp.Cmp(rec.BigInt()) != 0 {
+		t.Errorf("\n%v - %v (bit cap %d)\nexpected %v, %t\nreceived %v, %t\n", a, b, a.BitCap(), exp, expOver, rec, recOver)
+	}
+
+	if exp, rec := x.Cmp(y), a.Cmp(b); exp != rec {
+		t.Errorf("\ncmp %v and %v\nexpected %d\nreceived %d\n", a, b, exp, rec)
+	}
+}
+
+// randomMask returns a bitmask with each bit set with probability one half.
+func randomMask(r *rand.Rand, bitCap int) *LMask {
+	a := Zero(bitCap)
+	for bit := 0; bit < bitCap; bit++ {
+		if r.Intn(2) == 0 {
+			a.SetBit(bit)
+		}
+	}
+
+	return a
+}
@@ -0,0 +1,192 @@
+package lmask
+
+import (
+	"fmt"
+	"math/bits"
+	"sync/atomic"
+)
+
+// atomicWordBitCap is the number of bits in each word of an AtomicMask.
+const atomicWordBitCap = 64
+
+// AtomicMask is a bitmask of fixed bit capacity that is safe for
+// concurrent use. Operations on a single bit are lock-free and atomic.
+// Operations on the whole bitmask, such as Or and Load, are atomic per
+// word, but not across words.
+type AtomicMask struct {
+	bitCap int
+	words  []atomic.Uint64
+}
+
+// NewAtomicMask returns a concurrency-safe bitmask with no bits set.
+func NewAtomicMask(bitCap int) *AtomicMask {
+	return &AtomicMask{
+		bitCap: bitCap,
+		words:  make([]atomic.Uint64, (bitCap+atomicWordBitCap-1)/atomicWordBitCap),
+	}
+}
+
+// And unsets each bit in a that is not set in b.
+func (a *AtomicMask) And(b *LMask) *AtomicMask {
+	if a.bitCap != b.bitCap {
+		panic(fmt.Errorf("%w: %d and %d", ErrUnequalBitCaps, a.bitCap, b.bitCap))
+	}
+
+	for i := 0; i < len(a.words); i++ {
+		a.words[i].And(b.word64(i))
+	}
+
+	return a
+}
+
+// BitCap returns the bit capacity.
+func (a *AtomicMask) BitCap() int {
+	return a.bitCap
+}
+
+// ClaimFirstClear atomically sets the lowest unset bit and returns it.
+// If every bit is set, then the bit capacity and false are returned.
+func (a *AtomicMask) ClaimFirstClear() (int, bool) {
+	for i := 0; i < len(a.words); i++ {
+		for {
+			w := a.words[i].Load()
+			if w == 1<<atomicWordBitCap-1 {
+				break
+			}
+
+			bit := i*atomicWordBitCap + bits.TrailingZeros64(^w)
+			if a.bitCap <= bit {
+				return a.bitCap, false
+			}
+
+			if a.words[i].CompareAndSwap(w, w|1<<(bit-i*atomicWordBitCap)) {
+				return bit, true
+			}
+		}
+	}
+
+	return a.bitCap, false
+}
+
+// ClrBit unsets a bit. If the bit is not on range [0, bitCap), then
+// ClrBit panics with an error wrapping ErrBitOutOfRange.
+func (a *AtomicMask) ClrBit(bit int) *AtomicMask {
+	a.TestAndClear(bit)
+	return a
+}
+
+// Count returns the number of bits set.
+func (a *AtomicMask) Count() int {
+	var c int
+	for i := 0; i < len(a.words); i++ {
+		c += bits.OnesCount64(a.words[i].Load())
+	}
+
+	return c
+}
+
+// Load returns a copy of the bitmask. Each word is loaded atomically,
+// but bits may change in other words while the copy is made.
+func (a *AtomicMask) Load() *LMask {
+	b := Zero(a.bitCap)
+	for i := 0; i < len(a.words); i++ {
+		b.setWord64(i, a.words[i].Load())
+	}
+
+	return b
+}
+
+// MasksBit determines if a bit is set in a. Bits not on range
+// [0, bitCap) are never set.
+func (a *AtomicMask) MasksBit(bit int) bool {
+	if bit < 0 || a.bitCap <= bit {
+		return false
+	}
+
+	k := bit / atomicWordBitCap
+	return a.words[k].Load()&(1<<(bit-k*atomicWordBitCap)) != 0
+}
+
+// Or sets each bit in a that is set in b.
+func (a *AtomicMask) Or(b *LMask) *AtomicMask {
+	if a.bitCap != b.bitCap {
+		panic(fmt.Errorf("%w: %d and %d", ErrUnequalBitCaps, a.bitCap, b.bitCap))
+	}
+
+	for i := 0; i < len(a.words); i++ {
+		a.words[i].Or(b.word64(i))
+	}
+
+	return a
+}
+
+// SetBit sets a bit in a. If the bit is not on range [0, bitCap), then
+// SetBit panics with an error wrapping ErrBitOutOfRange.
+func (a *AtomicMask) SetBit(bit int) *AtomicMask {
+	a.TestAndSet(bit)
+	return a
+}
+
+// Store sets the bits of a to those of b. Each word is stored
+// atomically, but not all words are stored at once.
+func (a *AtomicMask) Store(b *LMask) *AtomicMask {
+	if a.bitCap != b.bitCap {
+		panic(fmt.Errorf("%w: %d and %d", ErrUnequalBitCaps, a.bitCap, b.bitCap))
+	}
+
+	for i := 0; i < len(a.words); i++ {
+		a.words[i].Store(b.word64(i))
+	}
+
+	return a
+}
+
+// TestAndClear unsets a bit and returns whether it was set. If the bit
+// is not on range [0, bitCap), then TestAndClear panics with an error
+// wrapping ErrBitOutOfRange.
+func (a *AtomicMask) TestAndClear(bit int) bool {
+	if bit < 0 || a.bitCap <= bit {
+		panic(fmt.Errorf("%w: %d not on range [0, %d)", ErrBitOutOfRange, bit, a.bitCap))
+	}
+
+	k := bit / atomicWordBitCap
+	c := uint64(1) << (bit - k*atomicWordBitCap)
+	return a.words[k].And(^c)&c != 0
+}
+
+// TestAndSet sets a bit and returns whether it was already set. If the
+// bit is not on range [0, bitCap), then TestAndSet panics with an error
+// wrapping ErrBitOutOfRange.
+func (a *AtomicMask) TestAndSet(bit int) bool {
+	if bit < 0 || a.bitCap <= bit {
+		panic(fmt.Errorf("%w: %d not on range [0, %d)", ErrBitOutOfRange, bit, a.bitCap))
+	}
+
+	k := bit / atomicWordBitCap
+	c := uint64(1) << (bit - k*atomicWordBitCap)
+	return a.words[k].Or(c)&c != 0
+}
+
+// --------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------
+
+// setWord64 sets the ith 64-bit word of a bitmask. Bits beyond the bit
+// capacity are ignored.
+func (a *LMask) setWord64(i int, w uint64) {
+	for j, k := 0, i*atomicWordBitCap/WordBitCap; j < atomicWordBitCap/WordBitCap && k+j < len(a.words); j++ {
+		a.words[k+j] = uint(w >> (j * WordBitCap))
+	}
+
+	a.trim()
+}
+
+// word64 returns the ith 64-bit word of a bitmask.
+func (a *LMask) word64(i int) uint64 {
+	var w uint64
+	for j, k := 0, i*atomicWordBitCap/WordBitCap; j < atomicWordBitCap/WordBitCap && k+j < len(a.words); j++ {
+		w |= uint64(a.words[k+j]) << (j * WordBitCap)
+	}
+
+	return w
+}
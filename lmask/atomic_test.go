@@ -0,0 +1,109 @@
+package lmask
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAtomicMask(t *testing.T) {
+	for _, bitCap := range []int{0, 1, WordBitCap - 1, WordBitCap, 2*WordBitCap + 3} {
+		var (
+			a   = NewAtomicMask(bitCap)
+			exp = Zero(bitCap)
+		)
+
+		for bit := 0; bit < bitCap; bit += 3 {
+			if a.TestAndSet(bit) {
+				t.Errorf("\nexpected bit %d to be unset\n", bit)
+			}
+
+			if !a.TestAndSet(bit) {
+				t.Errorf("\nexpected bit %d to be set\n", bit)
+			}
+
+			exp.SetBit(bit)
+		}
+
+		if rec := a.Load(); !exp.Equals(rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+		}
+
+		if rec := a.Count(); exp.Count() != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp.Count(), rec)
+		}
+
+		for bit := 0; bit < bitCap; bit++ {
+			if exp.MasksBit(bit) != a.MasksBit(bit) {
+				t.Errorf("\nexpected bit %d masked to be %t\n", bit, exp.MasksBit(bit))
+			}
+		}
+
+		b := Max(bitCap).ClrBits(exp.Bits()...)
+		if rec := a.Or(b).Load(); !Max(bitCap).Equals(rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", Max(bitCap), rec)
+		}
+
+		if rec := a.And(b).Load(); !b.Equals(rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", b, rec)
+		}
+
+		for _, bit := range b.Bits() {
+			if !a.TestAndClear(bit) {
+				t.Errorf("\nexpected bit %d to be set\n", bit)
+			}
+		}
+
+		if rec := a.Store(exp).Load(); !exp.Equals(rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+		}
+	}
+}
+
+func TestAtomicMaskClaimFirstClear(t *testing.T) {
+	const (
+		bitCap  = 3*64 + 5
+		workers = 8
+	)
+
+	var (
+		a      = NewAtomicMask(bitCap).SetBit(1)
+		claims = make([][]int, workers)
+		wg     sync.WaitGroup
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for {
+				bit, ok := a.ClaimFirstClear()
+				if !ok {
+					return
+				}
+
+				claims[i] = append(claims[i], bit)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	rec := FromBits(bitCap, 1)
+	for i := 0; i < workers; i++ {
+		for _, bit := range claims[i] {
+			if rec.MasksBit(bit) {
+				t.Errorf("\nexpected bit %d to be claimed once\n", bit)
+			}
+
+			rec.SetBit(bit)
+		}
+	}
+
+	if !Max(bitCap).Equals(rec) {
+		t.Errorf("\nexpected %v\nreceived %v\n", Max(bitCap), rec)
+	}
+
+	if bit, ok := a.ClaimFirstClear(); ok || bit != bitCap {
+		t.Errorf("\nexpected %d, false\nreceived %d, %t\n", bitCap, bit, ok)
+	}
+}
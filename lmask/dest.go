@@ -0,0 +1,209 @@
+package lmask
+
+// --------------------------------------------------------------------
+// Constructors
+// --------------------------------------------------------------------
+
+// And returns a new bitmask with each bit set that is set in both a and
+// b. The bit capacities of a and b must be equal.
+func And(a, b *LMask) *LMask {
+	return new(LMask).AndOf(a, b)
+}
+
+// AndNot returns a new bitmask with each bit set that is set in a and
+// not in b. The bit capacities of a and b must be equal.
+func AndNot(a, b *LMask) *LMask {
+	return new(LMask).AndNotOf(a, b)
+}
+
+// NAnd returns a new bitmask with each bit set that is not set in both a
+// and b. The bit capacities of a and b must be equal.
+func NAnd(a, b *LMask) *LMask {
+	return new(LMask).NAndOf(a, b)
+}
+
+// NOr returns a new bitmask with each bit set that is set in neither a
+// nor b. The bit capacities of a and b must be equal.
+func NOr(a, b *LMask) *LMask {
+	return new(LMask).NOrOf(a, b)
+}
+
+// Not returns a new bitmask with each bit set that is not set in a.
+func Not(a *LMask) *LMask {
+	return new(LMask).NotOf(a)
+}
+
+// Or returns a new bitmask with each bit set that is set in either a or
+// b. The bit capacities of a and b must be equal.
+func Or(a, b *LMask) *LMask {
+	return new(LMask).OrOf(a, b)
+}
+
+// XNOr returns a new bitmask with each bit set that is either set or
+// unset in both a and b. The bit capacities of a and b must be equal.
+func XNOr(a, b *LMask) *LMask {
+	return new(LMask).XNOrOf(a, b)
+}
+
+// XOr returns a new bitmask with each bit set that is set in exactly one
+// of a and b. The bit capacities of a and b must be equal.
+func XOr(a, b *LMask) *LMask {
+	return new(LMask).XOrOf(a, b)
+}
+
+// --------------------------------------------------------------------
+// Destination functionality
+// --------------------------------------------------------------------
+
+// AndOf sets a to the bits set in both b and c, reusing the words of a.
+// The bit capacities of b and c must be equal. Either may be a.
+func (a *LMask) AndOf(b, c *LMask) *LMask {
+	if b.bitCap != c.bitCap {
+		panic(uneqBitCaps(b, c))
+	}
+
+	a.reset(b.bitCap)
+	for i := 0; i < len(a.words); i++ {
+		a.words[i] = b.words[i] & c.words[i]
+	}
+
+	return a.modified()
+}
+
+// AndNotOf sets a to the bits set in b and not in c, reusing the words
+// of a. The bit capacities of b and c must be equal. Either may be a.
+func (a *LMask) AndNotOf(b, c *LMask) *LMask {
+	if b.bitCap != c.bitCap {
+		panic(uneqBitCaps(b, c))
+	}
+
+	a.reset(b.bitCap)
+	for i := 0; i < len(a.words); i++ {
+		a.words[i] = b.words[i] &^ c.words[i]
+	}
+
+	return a.modified()
+}
+
+// CopyOf sets a to a copy of b, reusing the words of a.
+func (a *LMask) CopyOf(b *LMask) *LMask {
+	a.reset(b.bitCap)
+	copy(a.words, b.words)
+	return a.modified()
+}
+
+// LShOf sets a to b with all set bits shifted to the left a given number
+// of bits, reusing the words of a. The operand may be a.
+func (a *LMask) LShOf(b *LMask, bits int) *LMask {
+	return a.CopyOf(b).LSh(bits)
+}
+
+// NAndOf sets a to the bits not set in both b and c, reusing the words
+// of a. The bit capacities of b and c must be equal. Either may be a.
+func (a *LMask) NAndOf(b, c *LMask) *LMask {
+	if b.bitCap != c.bitCap {
+		panic(uneqBitCaps(b, c))
+	}
+
+	a.reset(b.bitCap)
+	for i := 0; i < len(a.words); i++ {
+		a.words[i] = ^(b.words[i] & c.words[i])
+	}
+
+	return a.trim()
+}
+
+// NOrOf sets a to the bits set in neither b nor c, reusing the words of
+// a. The bit capacities of b and c must be equal. Either may be a.
+func (a *LMask) NOrOf(b, c *LMask) *LMask {
+	if b.bitCap != c.bitCap {
+		panic(uneqBitCaps(b, c))
+	}
+
+	a.reset(b.bitCap)
+	for i := 0; i < len(a.words); i++ {
+		a.words[i] = ^(b.words[i] | c.words[i])
+	}
+
+	return a.trim()
+}
+
+// NotOf sets a to the bits not set in b, reusing the words of a. The
+// operand may be a.
+func (a *LMask) NotOf(b *LMask) *LMask {
+	a.reset(b.bitCap)
+	for i := 0; i < len(a.words); i++ {
+		a.words[i] = ^b.words[i]
+	}
+
+	return a.trim()
+}
+
+// OrOf sets a to the bits set in either b or c, reusing the words of a.
+// The bit capacities of b and c must be equal. Either may be a.
+func (a *LMask) OrOf(b, c *LMask) *LMask {
+	if b.bitCap != c.bitCap {
+		panic(uneqBitCaps(b, c))
+	}
+
+	a.reset(b.bitCap)
+	for i := 0; i < len(a.words); i++ {
+		a.words[i] = b.words[i] | c.words[i]
+	}
+
+	return a.modified()
+}
+
+// RShOf sets a to b with all set bits shifted to the right a given
+// number of bits, reusing the words of a. The operand may be a.
+func (a *LMask) RShOf(b *LMask, bits int) *LMask {
+	return a.CopyOf(b).RSh(bits)
+}
+
+// XNOrOf sets a to the bits either set or unset in both b and c, reusing
+// the words of a. The bit capacities of b and c must be equal. Either
+// may be a.
+func (a *LMask) XNOrOf(b, c *LMask) *LMask {
+	if b.bitCap != c.bitCap {
+		panic(uneqBitCaps(b, c))
+	}
+
+	a.reset(b.bitCap)
+	for i := 0; i < len(a.words); i++ {
+		a.words[i] = ^(b.words[i] ^ c.words[i])
+	}
+
+	return a.trim()
+}
+
+// XOrOf sets a to the bits set in exactly one of b and c, reusing the
+// words of a. The bit capacities of b and c must be equal. Either may be
+// a.
+func (a *LMask) XOrOf(b, c *LMask) *LMask {
+	if b.bitCap != c.bitCap {
+		panic(uneqBitCaps(b, c))
+	}
+
+	a.reset(b.bitCap)
+	for i := 0; i < len(a.words); i++ {
+		a.words[i] = b.words[i] ^ c.words[i]
+	}
+
+	return a.modified()
+}
+
+// --------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------
+
+// reset sets the bit capacity of a, reusing its words if there is enough
+// room and otherwise allocating new words. The values of the words are
+// left for the caller to overwrite.
+func (a *LMask) reset(bitCap int) {
+	n := (bitCap + WordBitCap - 1) / WordBitCap
+	if cap(a.words) < n {
+		a.words = make([]uint, n)
+	}
+
+	a.bitCap, a.words = bitCap, a.words[:n]
+}
@@ -0,0 +1,85 @@
+package lmask
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDestination(t *testing.T) {
+	type testCase struct {
+		name string
+		exp  func(a, b *LMask) *LMask
+		of   func(dst, a, b *LMask) *LMask
+		fn   func(a, b *LMask) *LMask
+	}
+
+	tcs := []testCase{
+		{name: "and", exp: (*LMask).And, of: (*LMask).AndOf, fn: And},
+		{name: "and not", exp: (*LMask).AndNot, of: (*LMask).AndNotOf, fn: AndNot},
+		{name: "nand", exp: (*LMask).NAnd, of: (*LMask).NAndOf, fn: NAnd},
+		{name: "nor", exp: (*LMask).NOr, of: (*LMask).NOrOf, fn: NOr},
+		{name: "or", exp: (*LMask).Or, of: (*LMask).OrOf, fn: Or},
+		{name: "xnor", exp: (*LMask).XNOr, of: (*LMask).XNOrOf, fn: XNOr},
+		{name: "xor", exp: (*LMask).XOr, of: (*LMask).XOrOf, fn: XOr},
+		{
+			name: "not",
+			exp:  func(a, _ *LMask) *LMask { return a.Not() },
+			of:   func(dst, a, _ *LMask) *LMask { return dst.NotOf(a) },
+			fn:   func(a, _ *LMask) *LMask { return Not(a) },
+		},
+		{
+			name: "left shift",
+			exp:  func(a, _ *LMask) *LMask { return a.LSh(WordBitCap + 3) },
+			of:   func(dst, a, _ *LMask) *LMask { return dst.LShOf(a, WordBitCap+3) },
+			fn:   func(a, _ *LMask) *LMask { return a.Copy().LSh(WordBitCap + 3) },
+		},
+		{
+			name: "right shift",
+			exp:  func(a, _ *LMask) *LMask { return a.RSh(5) },
+			of:   func(dst, a, _ *LMask) *LMask { return dst.RShOf(a, 5) },
+			fn:   func(a, _ *LMask) *LMask { return a.Copy().RSh(5) },
+		},
+	}
+
+	r := rand.New(rand.NewSource(0))
+	for _, bitCap := range []int{0, 1, WordBitCap, 3*WordBitCap + 5} {
+		a, b := randomMask(r, bitCap), randomMask(r, bitCap)
+		for _, tc := range tcs {
+			var (
+				a0, b0 = a.Copy(), b.Copy()
+				exp    = tc.exp(a.Copy(), b)
+			)
+
+			// A destination of any size, including the zero value, is
+			// resized to the operands.
+			for _, dst := range []*LMask{{}, Zero(2 * WordBitCap), Max(5 * WordBitCap)} {
+				if rec := tc.of(dst, a, b); rec != dst || !exp.Equals(rec) {
+					t.Errorf("\n%s (bit cap %d)\nexpected %v\nreceived %v\n", tc.name, bitCap, exp, rec)
+				}
+			}
+
+			if rec := tc.fn(a, b); !exp.Equals(rec) {
+				t.Errorf("\n%s (bit cap %d)\nexpected %v\nreceived %v\n", tc.name, bitCap, exp, rec)
+			}
+
+			if !a0.Equals(a) || !b0.Equals(b) {
+				t.Errorf("\n%s (bit cap %d) modified its operands\n", tc.name, bitCap)
+			}
+
+			// The destination may be an operand.
+			if rec := tc.of(a0, a0, b0); !exp.Equals(rec) {
+				t.Errorf("\n%s (bit cap %d)\nexpected %v\nreceived %v\n", tc.name, bitCap, exp, rec)
+			}
+
+			if rec := tc.of(b0, a, b0); !exp.Equals(rec) {
+				t.Errorf("\n%s (bit cap %d)\nexpected %v\nreceived %v\n", tc.name, bitCap, exp, rec)
+			}
+		}
+	}
+
+	// Reusing a destination does not allocate.
+	a, b, dst := randomMask(r, 1<<10), randomMask(r, 1<<10), Zero(1<<10)
+	if n := testing.AllocsPerRun(10, func() { dst.AndOf(a, b).OrOf(dst, a).NotOf(dst) }); n != 0 {
+		t.Errorf("\nexpected no allocations\nreceived %f\n", n)
+	}
+}
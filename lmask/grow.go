@@ -0,0 +1,265 @@
+package lmask
+
+import (
+	"fmt"
+	"iter"
+)
+
+// GrowMask is a bitmask having arbitrary precision whose bit capacity
+// grows as bits are set. Setting a bit beyond the bit capacity extends
+// the bit capacity to include it, doubling the underlying words as
+// needed. Logic operations treat any bits beyond a bitmask's bit
+// capacity as unset, so bitmasks of different bit capacities may be
+// combined.
+type GrowMask struct {
+	m LMask
+}
+
+// --------------------------------------------------------------------
+// Constructors
+// --------------------------------------------------------------------
+
+// NewGrowMask returns a growable bitmask with the specified bits set.
+func NewGrowMask(bits ...int) *GrowMask {
+	return (&GrowMask{}).SetBits(bits...)
+}
+
+// GrowMask returns a growable copy of a bitmask.
+func (a *LMask) GrowMask() *GrowMask {
+	return &GrowMask{m: *a.Copy()}
+}
+
+// --------------------------------------------------------------------
+// Logic functionality
+// --------------------------------------------------------------------
+
+// And sets each bit in a if the bit in b is also set. Otherwise, the
+// bit in a is unset.
+func (a *GrowMask) And(b *GrowMask) *GrowMask {
+	n := min(len(a.m.words), len(b.m.words))
+	for i := 0; i < n; i++ {
+		a.m.words[i] &= b.m.words[i]
+	}
+
+	for i := n; i < len(a.m.words); i++ {
+		a.m.words[i] = 0
+	}
+
+	a.m.modified()
+	return a
+}
+
+// AndNot sets each bit in a if the bit in a is set and the bit in b
+// is not set. Otherwise, the bit in a is unset.
+func (a *GrowMask) AndNot(b *GrowMask) *GrowMask {
+	n := min(len(a.m.words), len(b.m.words))
+	for i := 0; i < n; i++ {
+		a.m.words[i] &^= b.m.words[i]
+	}
+
+	a.m.modified()
+	return a
+}
+
+// Or sets each bit in a if either bit in a or b is set. Otherwise,
+// the bit in a is unset. The bit capacity grows to that of b if it is
+// larger.
+func (a *GrowMask) Or(b *GrowMask) *GrowMask {
+	a.grow(b.m.bitCap)
+	for i := 0; i < len(b.m.words); i++ {
+		a.m.words[i] |= b.m.words[i]
+	}
+
+	a.m.modified()
+	return a
+}
+
+// XOr sets each bit in a if exactly one bit in a and b is set.
+// Otherwise, the bit in a is unset. The bit capacity grows to that of b
+// if it is larger.
+func (a *GrowMask) XOr(b *GrowMask) *GrowMask {
+	a.grow(b.m.bitCap)
+	for i := 0; i < len(b.m.words); i++ {
+		a.m.words[i] ^= b.m.words[i]
+	}
+
+	a.m.modified()
+	return a
+}
+
+// --------------------------------------------------------------------
+// Additional functionality
+// --------------------------------------------------------------------
+
+// All returns an iterator over the set bits in increasing order.
+func (a *GrowMask) All() iter.Seq[int] {
+	return a.m.All()
+}
+
+// Backward returns an iterator over the set bits in decreasing order.
+func (a *GrowMask) Backward() iter.Seq[int] {
+	return a.m.Backward()
+}
+
+// BitCap returns the bit capacity, which is one more than the largest
+// bit ever set.
+func (a *GrowMask) BitCap() int {
+	return a.m.bitCap
+}
+
+// BitLen returns the minimum number of bits required to represent a
+// bitmask exactly.
+func (a *GrowMask) BitLen() int {
+	return a.m.BitLen()
+}
+
+// Bits returns the bits that are set in a bitmask.
+func (a *GrowMask) Bits() []int {
+	return a.m.Bits()
+}
+
+// Clr unsets each bit in a that is set in b.
+func (a *GrowMask) Clr(b *GrowMask) *GrowMask {
+	return a.AndNot(b)
+}
+
+// ClrBit unsets a bit. Bits beyond the bit capacity are already unset.
+// If the bit is negative, then ClrBit panics with an error wrapping
+// ErrBitOutOfRange.
+func (a *GrowMask) ClrBit(bit int) *GrowMask {
+	if bit < 0 {
+		panic(fmt.Errorf("%w: %d is negative", ErrBitOutOfRange, bit))
+	}
+
+	if bit < a.m.bitCap {
+		a.m.ClrBit(bit)
+	}
+
+	return a
+}
+
+// ClrBits unsets several bits.
+func (a *GrowMask) ClrBits(bits ...int) *GrowMask {
+	for i := 0; i < len(bits); i++ {
+		a.ClrBit(bits[i])
+	}
+
+	return a
+}
+
+// Copy returns a copy of a bitmask.
+func (a *GrowMask) Copy() *GrowMask {
+	return a.m.GrowMask()
+}
+
+// Count returns the number of bits set.
+func (a *GrowMask) Count() int {
+	return a.m.Count()
+}
+
+// Equals determines if two bitmasks have the same bits set. Unlike
+// LMask, the bit capacities are not compared.
+func (a *GrowMask) Equals(b *GrowMask) bool {
+	if len(b.m.words) < len(a.m.words) {
+		a, b = b, a
+	}
+
+	for i := 0; i < len(a.m.words); i++ {
+		if a.m.words[i] != b.m.words[i] {
+			return false
+		}
+	}
+
+	for i := len(a.m.words); i < len(b.m.words); i++ {
+		if b.m.words[i] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LMask returns a copy of a bitmask having the same bit capacity.
+func (a *GrowMask) LMask() *LMask {
+	return a.m.Copy()
+}
+
+// MasksBit determines if a bit is set in a.
+func (a *GrowMask) MasksBit(bit int) bool {
+	return a.m.MasksBit(bit)
+}
+
+// NextBit returns the next set bit in a. If no set bit is next, then
+// the bit capacity is returned.
+func (a *GrowMask) NextBit(bit int) int {
+	return a.m.NextBit(bit)
+}
+
+// PrevBit returns the previous set bit in a. If no set bit is next,
+// then -1 is returned.
+func (a *GrowMask) PrevBit(bit int) int {
+	return a.m.PrevBit(bit)
+}
+
+// Set sets the bits of b in a. Any bits already set in a will remain
+// set.
+func (a *GrowMask) Set(b *GrowMask) *GrowMask {
+	return a.Or(b)
+}
+
+// SetBit sets a bit in a, growing the bit capacity to include it. If
+// the bit is negative, then SetBit panics with an error wrapping
+// ErrBitOutOfRange.
+func (a *GrowMask) SetBit(bit int) *GrowMask {
+	if bit < 0 {
+		panic(fmt.Errorf("%w: %d is negative", ErrBitOutOfRange, bit))
+	}
+
+	a.grow(bit + 1)
+	a.m.SetBit(bit)
+	return a
+}
+
+// SetBits sets several bits.
+func (a *GrowMask) SetBits(bits ...int) *GrowMask {
+	for i := 0; i < len(bits); i++ {
+		a.SetBit(bits[i])
+	}
+
+	return a
+}
+
+// String returns the base-10 integer representation of a bitmask.
+func (a *GrowMask) String() string {
+	return a.m.String()
+}
+
+// --------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------
+
+// grow increases the bit capacity to a given bit capacity if it is
+// larger. The underlying words at least double when reallocated, so
+// growing one bit at a time takes amortized constant time.
+func (a *GrowMask) grow(bitCap int) {
+	if bitCap <= a.m.bitCap {
+		return
+	}
+
+	n := bitCap / WordBitCap
+	if n*WordBitCap < bitCap {
+		n++
+	}
+
+	if cap(a.m.words) < n {
+		words := make([]uint, n, max(n, 2*cap(a.m.words)))
+		copy(words, a.m.words)
+		a.m.words = words
+	} else {
+		// Words beyond the length are never written, so are unset.
+		a.m.words = a.m.words[:n]
+	}
+
+	a.m.bitCap = bitCap
+	a.m.modified()
+}
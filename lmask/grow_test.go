@@ -0,0 +1,104 @@
+package lmask
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestGrowMaskSetBit(t *testing.T) {
+	type testCase struct {
+		bits      []int
+		expBitCap int
+	}
+
+	tcs := []testCase{
+		{bits: nil, expBitCap: 0},
+		{bits: []int{0}, expBitCap: 1},
+		{bits: []int{WordBitCap - 1, 1}, expBitCap: WordBitCap},
+		{bits: []int{5, 3*WordBitCap + 1, WordBitCap}, expBitCap: 3*WordBitCap + 2},
+	}
+
+	for _, tc := range tcs {
+		a := NewGrowMask(tc.bits...)
+		if rec := a.BitCap(); tc.expBitCap != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", tc.expBitCap, rec)
+		}
+
+		exp := slices.Sorted(slices.Values(tc.bits))
+		if rec := a.Bits(); !slices.Equal(exp, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		if rec := a.LMask(); !FromBits(tc.expBitCap, tc.bits...).Equals(rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", FromBits(tc.expBitCap, tc.bits...), rec)
+		}
+
+		// Clearing bits beyond the bit capacity has no effect.
+		if rec := a.ClrBit(tc.expBitCap + WordBitCap).BitCap(); tc.expBitCap != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", tc.expBitCap, rec)
+		}
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrBitOutOfRange) {
+			t.Errorf("\nexpected panic %v\nreceived %v\n", ErrBitOutOfRange, err)
+		}
+	}()
+
+	NewGrowMask(-1)
+}
+
+func TestGrowMaskLogic(t *testing.T) {
+	type testCase struct {
+		name string
+		op   func(a, b *GrowMask) *GrowMask
+		exp  []int
+	}
+
+	var (
+		a = []int{0, WordBitCap, 3 * WordBitCap}
+		b = []int{0, 1, 3 * WordBitCap, 5 * WordBitCap}
+	)
+
+	tcs := []testCase{
+		{name: "And", op: (*GrowMask).And, exp: []int{0, 3 * WordBitCap}},
+		{name: "AndNot", op: (*GrowMask).AndNot, exp: []int{WordBitCap}},
+		{name: "Or", op: (*GrowMask).Or, exp: []int{0, 1, WordBitCap, 3 * WordBitCap, 5 * WordBitCap}},
+		{name: "XOr", op: (*GrowMask).XOr, exp: []int{1, WordBitCap, 5 * WordBitCap}},
+	}
+
+	for _, tc := range tcs {
+		if rec := tc.op(NewGrowMask(a...), NewGrowMask(b...)); !NewGrowMask(tc.exp...).Equals(rec) {
+			t.Errorf("\n%s: expected %d\nreceived %d\n", tc.name, tc.exp, rec.Bits())
+		}
+
+		// Swapping operands grows the other way.
+		exp := tc.op(FromBits(5*WordBitCap+1, b...).GrowMask(), FromBits(3*WordBitCap+1, a...).GrowMask())
+		if rec := tc.op(NewGrowMask(b...), NewGrowMask(a...)); !exp.Equals(rec) {
+			t.Errorf("\n%s: expected %d\nreceived %d\n", tc.name, exp.Bits(), rec.Bits())
+		}
+	}
+}
+
+func TestGrowMaskFibonacci(t *testing.T) {
+	const maxCount = 30
+	fibs := NewGrowMask(1, 2)
+	for fibs.Count() < maxCount {
+		b := fibs.PrevBit(fibs.BitCap())
+		fibs.SetBit(b + fibs.PrevBit(b))
+	}
+
+	for a0, a1 := 0, 1; a1 < maxCount; a0, a1 = a1, a0+a1 {
+		if !fibs.MasksBit(a1) {
+			t.Errorf("\nexpected %d to be masked\n", a1)
+		}
+	}
+}
+
+func BenchmarkGrowMaskSetBit(b *testing.B) {
+	a := NewGrowMask()
+	for i := 0; i < b.N; i++ {
+		a.SetBit(i)
+	}
+}
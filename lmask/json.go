@@ -0,0 +1,99 @@
+package lmask
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// MaxExactJSONBitCap is the largest bit capacity ExactJSON decodes
+// without a hex field long enough to hold it. Larger bit capacities are
+// encoded with the hex field zero-padded to one digit per four bits, so
+// decoding never allocates more than the text accounts for.
+const MaxExactJSONBitCap = 1 << 24
+
+// ExactJSON wraps a bitmask to encode it as a json object recording the
+// exact bit capacity, such as {"bitCap":10,"hex":"20a"}. The hex field
+// is the base-16 integer representation of the bitmask. When decoding,
+// a list of set bits such as {"bitCap":10,"bits":[1,3,9]} is accepted in
+// place of the hex field, as is the base-10 integer representation
+// written by LMask.MarshalJSON.
+type ExactJSON struct {
+	*LMask
+}
+
+// exactJSON is the json object representing a bitmask.
+type exactJSON struct {
+	BitCap *int    `json:"bitCap"`
+	Hex    *string `json:"hex,omitempty"`
+	Bits   []int   `json:"bits,omitempty"`
+}
+
+// MarshalJSON returns json-encoded bytes representing a bitmask and its
+// bit capacity.
+func (e ExactJSON) MarshalJSON() ([]byte, error) {
+	if e.LMask == nil {
+		return []byte("null"), nil
+	}
+
+	hex := e.Fmt(16)
+	if n := (e.bitCap + 3) / 4; MaxExactJSONBitCap < e.bitCap && len(hex) < n {
+		hex = strings.Repeat("0", n-len(hex)) + hex
+	}
+
+	return json.Marshal(exactJSON{BitCap: &e.bitCap, Hex: &hex})
+}
+
+// UnmarshalJSON decodes json-encoded text into a bitmask. If the text is
+// "null", no action is taken and no error is returned.
+func (e *ExactJSON) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
+	if e.LMask == nil {
+		e.LMask = &LMask{}
+	}
+
+	if b = bytes.TrimSpace(b); len(b) == 0 || b[0] != '{' {
+		return e.LMask.UnmarshalJSON(b)
+	}
+
+	var v exactJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		// A bit capacity overflowing an int is also invalid.
+		return fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+	}
+
+	switch {
+	case v.BitCap == nil || *v.BitCap < 0:
+		return fmt.Errorf("%w: missing or negative bit capacity", ErrInvalidEncoding)
+	case MaxExactJSONBitCap < *v.BitCap && (v.Hex == nil || 4*len(*v.Hex) < *v.BitCap):
+		return fmt.Errorf("%w: bit capacity %d exceeds %d without a hex field to hold it", ErrInvalidEncoding, *v.BitCap, MaxExactJSONBitCap)
+	case v.Hex != nil && v.Bits != nil:
+		return fmt.Errorf("%w: both hex and bits given", ErrInvalidEncoding)
+	}
+
+	a := Zero(*v.BitCap)
+	if v.Hex != nil {
+		n, ok := big.NewInt(0).SetString(*v.Hex, 16)
+		if !ok || n.Sign() < 0 {
+			return fmt.Errorf("%w: invalid hex %q", ErrInvalidEncoding, *v.Hex)
+		}
+
+		if a.bitCap < n.BitLen() {
+			return fmt.Errorf("%w: hex %q exceeds bit capacity %d", ErrInvalidEncoding, *v.Hex, a.bitCap)
+		}
+
+		a = FromBigInt(n).SetBitCap(a.bitCap)
+	}
+
+	if _, err := a.TrySetBits(v.Bits...); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+	}
+
+	*e.LMask = *a
+	return nil
+}
@@ -0,0 +1,98 @@
+package lmask
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestExactJSON(t *testing.T) {
+	type testCase struct {
+		a   *LMask
+		exp string
+	}
+
+	tcs := []testCase{
+		{a: nil, exp: `null`},
+		{a: Zero(0), exp: `{"bitCap":0,"hex":"0"}`},
+		{a: Zero(10), exp: `{"bitCap":10,"hex":"0"}`},
+		{a: FromBits(10, 1, 3, 9), exp: `{"bitCap":10,"hex":"20a"}`},
+		{a: Max(4*WordBitCap + 1)},
+		{a: FromBits(MaxExactJSONBitCap+5, 3)},
+	}
+
+	for _, tc := range tcs {
+		b, err := json.Marshal(ExactJSON{tc.a})
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if tc.exp != "" && tc.exp != string(b) {
+			t.Errorf("\nexpected %s\nreceived %s\n", tc.exp, b)
+		}
+
+		var rec ExactJSON
+		if err := json.Unmarshal(b, &rec); err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if tc.a == nil {
+			if rec.LMask != nil {
+				t.Errorf("\nexpected nil\nreceived %v\n", rec.LMask)
+			}
+
+			continue
+		}
+
+		if !tc.a.Equals(rec.LMask) {
+			t.Errorf("\nexpected %v (bit cap %d)\nreceived %v (bit cap %d)\n", tc.a, tc.a.BitCap(), rec.LMask, rec.BitCap())
+		}
+	}
+}
+
+func TestExactJSONDecode(t *testing.T) {
+	type testCase struct {
+		s   string
+		exp *LMask
+		err error
+	}
+
+	tcs := []testCase{
+		{s: `{"bitCap":10,"bits":[1,3,9]}`, exp: FromBits(10, 1, 3, 9)},
+		{s: ` {"bitCap": 70, "bits": []} `, exp: Zero(70)},
+		{s: `{"bitCap":10,"hex":"20A"}`, exp: FromBits(10, 1, 3, 9)},
+		{s: `10`, exp: FromBits(WordBitCap, 1, 3)},
+		{s: `{"bits":[1]}`, err: ErrInvalidEncoding},
+		{s: `{"bitCap":-1}`, err: ErrInvalidEncoding},
+		{s: `{"bitCap":9000000000000000000,"hex":"0"}`, err: ErrInvalidEncoding},
+		{s: `{"bitCap":9000000000000000000,"bits":[1]}`, err: ErrInvalidEncoding},
+		{s: `{"bitCap":16777217,"hex":"1"}`, err: ErrInvalidEncoding},
+		{s: `{"bitCap":10,"bits":[10]}`, err: ErrInvalidEncoding},
+		{s: `{"bitCap":10,"hex":"400"}`, err: ErrInvalidEncoding},
+		{s: `{"bitCap":10,"hex":"xyz"}`, err: ErrInvalidEncoding},
+		{s: `{"bitCap":10,"hex":"1","bits":[1]}`, err: ErrInvalidEncoding},
+	}
+
+	for _, tc := range tcs {
+		var rec ExactJSON
+		err := json.Unmarshal([]byte(tc.s), &rec)
+		if tc.err != nil {
+			if !errors.Is(err, tc.err) {
+				t.Errorf("\nexpected %v\nreceived %v\n", tc.err, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if !tc.exp.Equals(rec.LMask) {
+			t.Errorf("\nexpected %v (bit cap %d)\nreceived %v (bit cap %d)\n", tc.exp, tc.exp.BitCap(), rec.LMask, rec.BitCap())
+		}
+	}
+}
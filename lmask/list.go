@@ -0,0 +1,94 @@
+package lmask
+
+import (
+	"math/bits"
+
+	"github.com/nathangreene3/bitmask/internal/list"
+)
+
+// ErrSyntax indicates text is not a valid list or hex mask.
+var ErrSyntax = list.ErrSyntax
+
+// ParseError describes a failure to parse a list or hex mask. The
+// position is the byte offset into the text at which parsing failed.
+// The error wraps ErrSyntax or ErrBitOutOfRange.
+type ParseError = list.ParseError
+
+// ParseList returns a bitmask from a list of bits and inclusive ranges
+// of bits separated by commas, such as "0-3,8,10-15". This is the format
+// Linux uses for cpu lists. Surrounding whitespace is ignored and the
+// empty list is the zero bitmask. A *ParseError is returned if the text
+// is invalid or a bit is not on range [0, bitCap).
+func ParseList(bitCap int, s string) (*LMask, error) {
+	a := Zero(bitCap)
+	err := list.Parse(s, func(lo, hi int) error {
+		if err := a.checkBits(lo, hi); err != nil {
+			return err
+		}
+
+		a.SetRange(lo, hi+1)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// FormatList returns the set bits as a list of bits and inclusive
+// ranges of bits separated by commas, such as "0-3,8,10-15". The zero
+// bitmask is formatted as the empty string.
+func (a *LMask) FormatList() string {
+	var b []byte
+	for lo := a.NextBit(-1); lo < a.bitCap; {
+		hi := lo
+		for next := a.NextBit(hi); next == hi+1 && next < a.bitCap; next = a.NextBit(hi) {
+			hi = next
+		}
+
+		b = list.AppendRange(b, lo, hi)
+		lo = a.NextBit(hi)
+	}
+
+	return string(b)
+}
+
+// ParseHexMask returns a bitmask from 32-bit groups of hex digits
+// separated by commas, most significant group first, such as
+// "00000000,0000ff0f". This is the format Linux uses for cpu masks.
+// Groups may have fewer than eight digits and surrounding whitespace is
+// ignored. A *ParseError is returned if the text is invalid or a bit is
+// not on range [0, bitCap).
+func ParseHexMask(bitCap int, s string) (*LMask, error) {
+	a := Zero(bitCap)
+	err := list.ParseHex(s, func(i int, group uint32) error {
+		for ; group != 0; group &= group - 1 {
+			bit := i*32 + bits.TrailingZeros32(group)
+			if err := a.checkBit(bit); err != nil {
+				return err
+			}
+
+			a.SetBit(bit)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// FormatHexMask returns the bitmask as 32-bit groups of eight hex digits
+// separated by commas, most significant group first, such as
+// "00000000,0000ff0f". There is one group for every 32 bits of capacity
+// and at least one group.
+func (a *LMask) FormatHexMask() string {
+	return list.FormatHex(max((a.bitCap+31)/32, 1), func(i int) uint32 {
+		return uint32(a.word64(i/2) >> (i % 2 * 32))
+	})
+}
@@ -0,0 +1,158 @@
+package lmask
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	type testCase struct {
+		a   *LMask
+		exp string
+	}
+
+	tcs := []testCase{
+		{a: Zero(0), exp: ""},
+		{a: Zero(10), exp: ""},
+		{a: FromBits(10, 0), exp: "0"},
+		{a: FromBits(20, 0, 1, 2, 3, 8, 10, 11, 12, 13, 14, 15), exp: "0-3,8,10-15"},
+		{a: FromBits(2*WordBitCap, WordBitCap-1, WordBitCap, 2*WordBitCap-1), exp: fmt.Sprintf("%d-%d,%d", WordBitCap-1, WordBitCap, 2*WordBitCap-1)},
+		{a: Max(3*WordBitCap + 1), exp: fmt.Sprintf("0-%d", 3*WordBitCap)},
+	}
+
+	for _, tc := range tcs {
+		rec := tc.a.FormatList()
+		if tc.exp != rec {
+			t.Errorf("\nexpected %q\nreceived %q\n", tc.exp, rec)
+		}
+
+		b, err := ParseList(tc.a.BitCap(), rec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if !tc.a.Equals(b) {
+			t.Errorf("\nexpected %v\nreceived %v\n", tc.a, b)
+		}
+	}
+}
+
+func TestParseList(t *testing.T) {
+	type testCase struct {
+		s   string
+		exp *LMask
+		pos int
+		err error
+	}
+
+	tcs := []testCase{
+		{s: " 1,3-5\n", exp: FromBits(10, 1, 3, 4, 5)},
+		{s: "5-5,1-2,2", exp: FromBits(10, 1, 2, 5)},
+		{s: "9", exp: FromBits(10, 9)},
+		{s: "10", pos: 0, err: ErrBitOutOfRange},
+		{s: "1,8-10", pos: 2, err: ErrBitOutOfRange},
+		{s: "1,,2", pos: 2, err: ErrSyntax},
+		{s: "1,", pos: 2, err: ErrSyntax},
+		{s: "-1", pos: 0, err: ErrSyntax},
+		{s: "0,5-3", pos: 4, err: ErrSyntax},
+		{s: " 0,2-x", pos: 5, err: ErrSyntax},
+		{s: "0-1-2", pos: 2, err: ErrSyntax},
+		{s: "+1", pos: 0, err: ErrSyntax},
+	}
+
+	for _, tc := range tcs {
+		rec, err := ParseList(10, tc.s)
+		if tc.err != nil {
+			var pe *ParseError
+			if !errors.Is(err, tc.err) || !errors.As(err, &pe) || tc.pos != pe.Pos {
+				t.Errorf("\nexpected %v at position %d\nreceived %v\n", tc.err, tc.pos, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if !tc.exp.Equals(rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", tc.exp, rec)
+		}
+	}
+}
+
+func TestHexMask(t *testing.T) {
+	type testCase struct {
+		a   *LMask
+		exp string
+	}
+
+	tcs := []testCase{
+		{a: Zero(0), exp: "00000000"},
+		{a: FromBits(10, 0, 9), exp: "00000201"},
+		{a: FromBits(64, 0, 1, 2, 3, 8, 10, 11, 12, 13, 14, 15), exp: "00000000,0000fd0f"},
+		{a: FromBits(65, 31, 32, 64), exp: "00000001,00000001,80000000"},
+		{a: Max(96), exp: "ffffffff,ffffffff,ffffffff"},
+	}
+
+	for _, tc := range tcs {
+		rec := tc.a.FormatHexMask()
+		if tc.exp != rec {
+			t.Errorf("\nexpected %q\nreceived %q\n", tc.exp, rec)
+		}
+
+		b, err := ParseHexMask(tc.a.BitCap(), rec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if !tc.a.Equals(b) {
+			t.Errorf("\nexpected %v\nreceived %v\n", tc.a, b)
+		}
+	}
+}
+
+func TestParseHexMask(t *testing.T) {
+	type testCase struct {
+		s   string
+		exp *LMask
+		pos int
+		err error
+	}
+
+	tcs := []testCase{
+		{s: "ff0f\n", exp: FromBits(40, 0, 1, 2, 3, 8, 9, 10, 11, 12, 13, 14, 15)},
+		{s: "1,0", exp: FromBits(40, 32)},
+		{s: "00000000,00000000,1", exp: FromBits(40, 0)},
+		{s: "100,0", pos: 0, err: ErrBitOutOfRange},
+		{s: "", pos: 0, err: ErrSyntax},
+		{s: "1,,0", pos: 2, err: ErrSyntax},
+		{s: "1,0000000000", pos: 2, err: ErrSyntax},
+		{s: "1,00g0", pos: 4, err: ErrSyntax},
+	}
+
+	for _, tc := range tcs {
+		rec, err := ParseHexMask(40, tc.s)
+		if tc.err != nil {
+			var pe *ParseError
+			if !errors.Is(err, tc.err) || !errors.As(err, &pe) || tc.pos != pe.Pos {
+				t.Errorf("\nexpected %v at position %d\nreceived %v\n", tc.err, tc.pos, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if !tc.exp.Equals(rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", tc.exp, rec)
+		}
+	}
+}
@@ -1,8 +1,14 @@
 package lmask
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"iter"
 	"math/big"
 	"math/bits"
+
+	"github.com/nathangreene3/bitmask/internal/bounds"
 )
 
 const (
@@ -12,14 +18,39 @@ const (
 	// WordMax is the maximum word.
 	WordMax = 1<<WordBitCap - 1
 
-	// errUneqBitCaps indicates an operation has been applied on two or more bitmasks in which the bit capacities are required to be equal.
-	errUneqBitCaps = "unequal bit capacities"
+	// binaryVersion is the version of the binary encoding written by
+	// MarshalBinary.
+	binaryVersion = 1
+
+	// wordBytes is the number of bytes in a word.
+	wordBytes = WordBitCap / 8
+)
+
+var (
+	// ErrUnequalBitCaps indicates an operation has been applied on two or
+	// more bitmasks in which the bit capacities are required to be equal.
+	ErrUnequalBitCaps = errors.New("unequal bit capacities")
+
+	// ErrBitOutOfRange indicates a bit is not on range [0, bitCap). Every
+	// method taking a range of bits [lo, hi) panics with it if the range
+	// is non-empty and not within [0, bitCap). An empty range, where
+	// hi <= lo, is always allowed.
+	ErrBitOutOfRange = bounds.ErrBitOutOfRange
+
+	// ErrInvalidBitCap indicates an operation has been applied on a
+	// bitmask whose bit capacity it does not support.
+	ErrInvalidBitCap = errors.New("invalid bit capacity")
+
+	// ErrInvalidEncoding indicates data could not be decoded into a
+	// bitmask.
+	ErrInvalidEncoding = errors.New("invalid encoding")
 )
 
 // LMask is an implementation of a bitmask having arbitrary precision.
 type LMask struct {
 	bitCap int
 	words  []uint
+	index  rankIndex
 }
 
 // --------------------------------------------------------------------
@@ -45,7 +76,8 @@ func FromBits(bitCap int, bits ...int) *LMask {
 }
 
 // FromJSON returns a bitmask decoded from a json-encoded string. The
-// bit capacity will be a multiple of the word bit capacity.
+// bit capacity will be a multiple of the word bit capacity. To
+// preserve the exact bit capacity, encode and decode with ExactJSON.
 func FromJSON(s string) (*LMask, error) {
 	var a LMask
 	if err := a.UnmarshalText([]byte(s)); err != nil {
@@ -89,56 +121,56 @@ func Zero(bitCap int) *LMask {
 // bit in a is unset.
 func (a *LMask) And(b *LMask) *LMask {
 	if a.bitCap != b.bitCap {
-		panic(errUneqBitCaps)
+		panic(uneqBitCaps(a, b))
 	}
 
 	for i := 0; i < len(a.words); i++ {
 		a.words[i] &= b.words[i]
 	}
 
-	return a
+	return a.modified()
 }
 
 // AndNot sets each bit in a if the bit in a is set and the bit in b
 // is not set. Otherwise, the bit in a is unset.
 func (a *LMask) AndNot(b *LMask) *LMask {
 	if a.bitCap != b.bitCap {
-		panic(errUneqBitCaps)
+		panic(uneqBitCaps(a, b))
 	}
 
 	for i := 0; i < len(a.words); i++ {
 		a.words[i] &^= b.words[i]
 	}
 
-	return a
+	return a.modified()
 }
 
 // NAnd sets each bit in a if the bit is not set in both a and b.
 // Otherwise, the bit in a is unset.
 func (a *LMask) NAnd(b *LMask) *LMask {
 	if a.bitCap != b.bitCap {
-		panic(errUneqBitCaps)
+		panic(uneqBitCaps(a, b))
 	}
 
 	for i := 0; i < len(a.words); i++ {
 		a.words[i] = ^(a.words[i] & b.words[i])
 	}
 
-	return a
+	return a.trim()
 }
 
 // NOr sets each bit in a if the bit in a and b is unset. Otherwise,
 // the bit is unset.
 func (a *LMask) NOr(b *LMask) *LMask {
 	if a.bitCap != b.bitCap {
-		panic(errUneqBitCaps)
+		panic(uneqBitCaps(a, b))
 	}
 
 	for i := 0; i < len(a.words); i++ {
 		a.words[i] = ^(a.words[i] | b.words[i])
 	}
 
-	return a
+	return a.trim()
 }
 
 // Not inverts each bit in a.
@@ -154,48 +186,261 @@ func (a *LMask) Not() *LMask {
 // the bit in a is unset.
 func (a *LMask) Or(b *LMask) *LMask {
 	if a.bitCap != b.bitCap {
-		panic(errUneqBitCaps)
+		panic(uneqBitCaps(a, b))
 	}
 
 	for i := 0; i < len(a.words); i++ {
 		a.words[i] |= b.words[i]
 	}
 
-	return a
+	return a.modified()
 }
 
 // XNOr sets each bit in a if either both bits in a and b are set or
 // unset. Otherwise, the bit in a is unset.
 func (a *LMask) XNOr(b *LMask) *LMask {
 	if a.bitCap != b.bitCap {
-		panic(errUneqBitCaps)
+		panic(uneqBitCaps(a, b))
 	}
 
 	for i := 0; i < len(a.words); i++ {
 		a.words[i] = ^(a.words[i] ^ b.words[i])
 	}
 
-	return a
+	return a.trim()
 }
 
 // XOr sets each bit in a if exactly one bit in a and b is set.
 // Otherwise, the bit in a is unset.
 func (a *LMask) XOr(b *LMask) *LMask {
 	if a.bitCap != b.bitCap {
-		panic(errUneqBitCaps)
+		panic(uneqBitCaps(a, b))
 	}
 
 	for i := 0; i < len(a.words); i++ {
 		a.words[i] ^= b.words[i]
 	}
 
-	return a
+	return a.modified()
+}
+
+// --------------------------------------------------------------------
+// Checked logic functionality
+// --------------------------------------------------------------------
+//
+// Each of the following applies the operation of the same name, but
+// returns an error wrapping ErrUnequalBitCaps or ErrBitOutOfRange rather
+// than panicking. To operate on bitmasks of different bit capacities,
+// call Widen first.
+
+// TryAnd applies And if the bit capacities are equal. Otherwise, a is
+// unchanged and an error is returned.
+func (a *LMask) TryAnd(b *LMask) (*LMask, error) {
+	if a.bitCap != b.bitCap {
+		return nil, uneqBitCaps(a, b)
+	}
+
+	return a.And(b), nil
+}
+
+// TryAndNot applies AndNot if the bit capacities are equal. Otherwise, a is
+// unchanged and an error is returned.
+func (a *LMask) TryAndNot(b *LMask) (*LMask, error) {
+	if a.bitCap != b.bitCap {
+		return nil, uneqBitCaps(a, b)
+	}
+
+	return a.AndNot(b), nil
+}
+
+// TryNAnd applies NAnd if the bit capacities are equal. Otherwise, a is
+// unchanged and an error is returned.
+func (a *LMask) TryNAnd(b *LMask) (*LMask, error) {
+	if a.bitCap != b.bitCap {
+		return nil, uneqBitCaps(a, b)
+	}
+
+	return a.NAnd(b), nil
+}
+
+// TryNOr applies NOr if the bit capacities are equal. Otherwise, a is
+// unchanged and an error is returned.
+func (a *LMask) TryNOr(b *LMask) (*LMask, error) {
+	if a.bitCap != b.bitCap {
+		return nil, uneqBitCaps(a, b)
+	}
+
+	return a.NOr(b), nil
+}
+
+// TryOr applies Or if the bit capacities are equal. Otherwise, a is
+// unchanged and an error is returned.
+func (a *LMask) TryOr(b *LMask) (*LMask, error) {
+	if a.bitCap != b.bitCap {
+		return nil, uneqBitCaps(a, b)
+	}
+
+	return a.Or(b), nil
+}
+
+// TryXNOr applies XNOr if the bit capacities are equal. Otherwise, a is
+// unchanged and an error is returned.
+func (a *LMask) TryXNOr(b *LMask) (*LMask, error) {
+	if a.bitCap != b.bitCap {
+		return nil, uneqBitCaps(a, b)
+	}
+
+	return a.XNOr(b), nil
+}
+
+// TryXOr applies XOr if the bit capacities are equal. Otherwise, a is
+// unchanged and an error is returned.
+func (a *LMask) TryXOr(b *LMask) (*LMask, error) {
+	if a.bitCap != b.bitCap {
+		return nil, uneqBitCaps(a, b)
+	}
+
+	return a.XOr(b), nil
+}
+
+// TryClrBit unsets a bit if it is on range [0, bitCap). Otherwise, a is
+// unchanged and an error wrapping ErrBitOutOfRange is returned.
+func (a *LMask) TryClrBit(bit int) (*LMask, error) {
+	if err := a.checkBit(bit); err != nil {
+		return nil, err
+	}
+
+	return a.ClrBit(bit), nil
+}
+
+// TryClrBits unsets several bits if each is on range [0, bitCap).
+// Otherwise, a is unchanged and an error wrapping ErrBitOutOfRange is
+// returned.
+func (a *LMask) TryClrBits(bits ...int) (*LMask, error) {
+	if err := a.checkBits(bits...); err != nil {
+		return nil, err
+	}
+
+	return a.ClrBits(bits...), nil
+}
+
+// TrySetBit sets a bit if it is on range [0, bitCap). Otherwise, a is
+// unchanged and an error wrapping ErrBitOutOfRange is returned.
+func (a *LMask) TrySetBit(bit int) (*LMask, error) {
+	if err := a.checkBit(bit); err != nil {
+		return nil, err
+	}
+
+	return a.SetBit(bit), nil
+}
+
+// TrySetBits sets several bits if each is on range [0, bitCap).
+// Otherwise, a is unchanged and an error wrapping ErrBitOutOfRange is
+// returned.
+func (a *LMask) TrySetBits(bits ...int) (*LMask, error) {
+	if err := a.checkBits(bits...); err != nil {
+		return nil, err
+	}
+
+	return a.SetBits(bits...), nil
+}
+
+// Widen sets the bit capacity of each bitmask to the largest bit
+// capacity among them. No set bits are lost.
+func Widen(masks ...*LMask) {
+	var bitCap int
+	for i := 0; i < len(masks); i++ {
+		bitCap = max(bitCap, masks[i].bitCap)
+	}
+
+	for i := 0; i < len(masks); i++ {
+		masks[i].SetBitCap(bitCap)
+	}
 }
 
 // --------------------------------------------------------------------
 // Additional functionality
 // --------------------------------------------------------------------
 
+// All returns an iterator over the set bits in increasing order.
+func (a *LMask) All() iter.Seq[int] {
+	return a.AllRange(0, a.bitCap)
+}
+
+// AllInRange determines if every bit on range [lo, hi) is set. An empty
+// range is always set.
+func (a *LMask) AllInRange(lo, hi int) bool {
+	if err := a.checkRange(lo, hi); err != nil {
+		panic(err)
+	}
+
+	lo, hi = clamp(lo, 0, a.bitCap), clamp(hi, 0, a.bitCap)
+	for k := lo / WordBitCap; k*WordBitCap < hi; k++ {
+		if m := wordMask(k, lo, hi); a.words[k]&m != m {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AllRange returns an iterator over the set bits on range [lo, hi) in
+// increasing order.
+func (a *LMask) AllRange(lo, hi int) iter.Seq[int] {
+	if err := a.checkRange(lo, hi); err != nil {
+		panic(err)
+	}
+
+	return func(yield func(int) bool) {
+		// The bit capacity may change between iterations.
+		lo, hi := clamp(lo, 0, a.bitCap), clamp(hi, 0, a.bitCap)
+		for bit := a.NextBit(lo - 1); bit < hi; bit = a.NextBit(bit) {
+			if !yield(bit) {
+				return
+			}
+		}
+	}
+}
+
+// AnyInRange determines if any bit on range [lo, hi) is set.
+func (a *LMask) AnyInRange(lo, hi int) bool {
+	if err := a.checkRange(lo, hi); err != nil {
+		panic(err)
+	}
+
+	lo, hi = clamp(lo, 0, a.bitCap), clamp(hi, 0, a.bitCap)
+	for k := lo / WordBitCap; k*WordBitCap < hi; k++ {
+		if a.words[k]&wordMask(k, lo, hi) != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Backward returns an iterator over the set bits in decreasing order.
+func (a *LMask) Backward() iter.Seq[int] {
+	return a.BackwardRange(0, a.bitCap)
+}
+
+// BackwardRange returns an iterator over the set bits on range
+// [lo, hi) in decreasing order.
+func (a *LMask) BackwardRange(lo, hi int) iter.Seq[int] {
+	if err := a.checkRange(lo, hi); err != nil {
+		panic(err)
+	}
+
+	return func(yield func(int) bool) {
+		// The bit capacity may change between iterations.
+		lo, hi := clamp(lo, 0, a.bitCap), clamp(hi, 0, a.bitCap)
+		for bit := a.PrevBit(hi); lo <= bit; bit = a.PrevBit(bit) {
+			if !yield(bit) {
+				return
+			}
+		}
+	}
+}
+
 // BigInt returns an equivalent big integer.
 func (a *LMask) BigInt() *big.Int {
 	if a == nil {
@@ -242,26 +487,47 @@ func (a *LMask) Clr(b *LMask) *LMask {
 	return a.AndNot(b)
 }
 
-// ClrBit unsets a bit.
+// ClrBit unsets a bit. If the bit is not on range [0, bitCap), then
+// ClrBit panics with an error wrapping ErrBitOutOfRange.
 func (a *LMask) ClrBit(bit int) *LMask {
-	if 0 < a.bitCap {
-		k := bit / WordBitCap
-		a.words[k] &^= 1 << (bit - k*WordBitCap)
+	if err := a.checkBit(bit); err != nil {
+		panic(err)
 	}
 
-	return a
+	k := bit / WordBitCap
+	a.words[k] &^= 1 << (bit - k*WordBitCap)
+	return a.modified()
 }
 
-// ClrBits unsets several bits.
+// ClrBits unsets several bits. If any bit is not on range [0, bitCap),
+// then ClrBits panics with an error wrapping ErrBitOutOfRange and no
+// bits are unset.
 func (a *LMask) ClrBits(bits ...int) *LMask {
-	if 0 < a.bitCap {
-		for i := 0; i < len(bits); i++ {
-			k := bits[i] / WordBitCap
-			a.words[k] &^= 1 << (bits[i] - k*WordBitCap)
-		}
+	if err := a.checkBits(bits...); err != nil {
+		panic(err)
 	}
 
-	return a
+	for i := 0; i < len(bits); i++ {
+		k := bits[i] / WordBitCap
+		a.words[k] &^= 1 << (bits[i] - k*WordBitCap)
+	}
+
+	return a.modified()
+}
+
+// ClrRange unsets the bits on range [lo, hi).
+func (a *LMask) ClrRange(lo, hi int) *LMask {
+	if err := a.checkRange(lo, hi); err != nil {
+		panic(err)
+	}
+
+	lo, hi = clamp(lo, 0, a.bitCap), clamp(hi, 0, a.bitCap)
+
+	for k := lo / WordBitCap; k*WordBitCap < hi; k++ {
+		a.words[k] &^= wordMask(k, lo, hi)
+	}
+
+	return a.modified()
 }
 
 // Copy returns a copy of a bitmask.
@@ -279,6 +545,50 @@ func (a *LMask) Count() int {
 	return c
 }
 
+// CountRange returns the number of bits set on range [lo, hi).
+func (a *LMask) CountRange(lo, hi int) int {
+	if err := a.checkRange(lo, hi); err != nil {
+		panic(err)
+	}
+
+	var c int
+	lo, hi = clamp(lo, 0, a.bitCap), clamp(hi, 0, a.bitCap)
+	for k := lo / WordBitCap; k*WordBitCap < hi; k++ {
+		c += bits.OnesCount(a.words[k] & wordMask(k, lo, hi))
+	}
+
+	return c
+}
+
+// Deposit scatters the low bits of a to the bits set in mask, in order.
+// That is, the ith lowest bit of a is moved to the ith lowest set bit of
+// mask. This is the PDEP instruction of BMI2 extended across words. The
+// bit capacities of a and mask must be equal.
+func (a *LMask) Deposit(mask *LMask) *LMask {
+	if a.bitCap != mask.bitCap {
+		panic(uneqBitCaps(a, mask))
+	}
+
+	var (
+		words = make([]uint, len(a.words))
+		i     int
+	)
+
+	for k := 0; k < len(mask.words); k++ {
+		for m := mask.words[k]; m != 0; {
+			// Deposit a run of n set bits beginning at bit p at once.
+			p := bits.TrailingZeros(m)
+			n := bits.TrailingZeros(^(m >> p))
+			words[k] |= a.bitsAt(i, n) << p
+			i += n
+			m &^= (1<<n - 1) << p
+		}
+	}
+
+	a.words = words
+	return a.modified()
+}
+
 // Equal determines if two bitmasks are equal. Equality is defined as
 // having the same bit capacity and the same bits set.
 func (a *LMask) Equals(b *LMask) bool {
@@ -299,6 +609,50 @@ func (a *LMask) Equals(b *LMask) bool {
 	return true
 }
 
+// Extract gathers the bits of a selected by mask into the low bits, in
+// order. That is, the bit of a at the ith lowest set bit of mask is
+// moved to bit i. This is the PEXT instruction of BMI2 extended across
+// words. The bit capacities of a and mask must be equal.
+func (a *LMask) Extract(mask *LMask) *LMask {
+	if a.bitCap != mask.bitCap {
+		panic(uneqBitCaps(a, mask))
+	}
+
+	var (
+		words = make([]uint, len(a.words))
+		i     int
+	)
+
+	for k := 0; k < len(mask.words); k++ {
+		for m := mask.words[k]; m != 0; {
+			// Extract a run of n set bits beginning at bit p at once.
+			p := bits.TrailingZeros(m)
+			n := bits.TrailingZeros(^(m >> p))
+			orBitsAt(words, i, a.words[k]>>p&(1<<n-1), n)
+			i += n
+			m &^= (1<<n - 1) << p
+		}
+	}
+
+	a.words = words
+	return a.modified()
+}
+
+// FlipRange inverts the bits on range [lo, hi).
+func (a *LMask) FlipRange(lo, hi int) *LMask {
+	if err := a.checkRange(lo, hi); err != nil {
+		panic(err)
+	}
+
+	lo, hi = clamp(lo, 0, a.bitCap), clamp(hi, 0, a.bitCap)
+
+	for k := lo / WordBitCap; k*WordBitCap < hi; k++ {
+		a.words[k] ^= wordMask(k, lo, hi)
+	}
+
+	return a.modified()
+}
+
 // Fmt returns a string formatted as the integer represented by a
 // bitmask in a given base. This supports bases on range [2, 62].
 func (a *LMask) Fmt(base int) string {
@@ -314,10 +668,18 @@ func (a *LMask) JSON() string {
 // LSh shifts all set bits by a given amount. That is, each set bit i
 // will be unset and bit i+bits will be set.
 func (a *LMask) LSh(bits int) *LMask {
+	if len(a.words)*WordBitCap <= bits {
+		for i := 0; i < len(a.words); i++ {
+			a.words[i] = 0
+		}
+
+		return a.modified()
+	}
+
 	if 0 < a.bitCap {
 		k := bits / WordBitCap
 		if 0 < k {
-			copy(a.words[:len(a.words)-k], a.words[k:])
+			copy(a.words[k:], a.words[:len(a.words)-k])
 			for i := 0; i < k; i++ {
 				a.words[i] = 0
 			}
@@ -325,19 +687,34 @@ func (a *LMask) LSh(bits int) *LMask {
 
 		if r := bits - k*WordBitCap; 0 < r {
 			d := WordBitCap - r
-			h0 := a.words[k] >> d
-			a.words[k] <<= r
-			for k++; k < len(a.words); k++ {
-				h1 := a.words[k] >> d
-				a.words[k] = (a.words[k] << r) | h0
-				h0 = h1
+			for i := len(a.words) - 1; k < i; i-- {
+				a.words[i] = (a.words[i] << r) | (a.words[i-1] >> d)
 			}
+
+			a.words[k] <<= r
 		}
 	}
 
 	return a.trim()
 }
 
+// MarshalBinary returns a compact binary encoding of a bitmask. The
+// encoding is a version byte, the bit capacity as an unsigned varint,
+// and then the bits packed into ceil(bitCap/8) bytes in little-endian
+// order. It does not depend on the word bit capacity, so it decodes the
+// same on all platforms.
+func (a *LMask) MarshalBinary() ([]byte, error) {
+	n := (a.bitCap + 7) / 8
+	b := make([]byte, 0, 1+binary.MaxVarintLen64+n)
+	b = append(b, binaryVersion)
+	b = binary.AppendUvarint(b, uint64(a.bitCap))
+	for i := 0; i < n; i++ {
+		b = append(b, byte(a.words[i/wordBytes]>>(8*(i%wordBytes))))
+	}
+
+	return b, nil
+}
+
 // MarshalText returns text representing a bitmask.
 func (a *LMask) MarshalText() ([]byte, error) {
 	if a == nil {
@@ -373,8 +750,13 @@ func (a *LMask) Masks(b *LMask) bool {
 	return true
 }
 
-// MasksBit determines if a bit is set in a.
+// MasksBit determines if a bit is set in a. Bits not on range
+// [0, bitCap) are never set.
 func (a *LMask) MasksBit(bit int) bool {
+	if bit < 0 || a.bitCap <= bit {
+		return false
+	}
+
 	k := bit / WordBitCap
 	c := uint(1 << (bit - k*WordBitCap))
 	return a.words[k]&c == c
@@ -425,10 +807,18 @@ func (a *LMask) PrevBit(bit int) int {
 // RSh shifts all set bits by a given amount. That is, each set bit i
 // will be unset and bit i-bits will be set.
 func (a *LMask) RSh(bits int) *LMask {
+	if len(a.words)*WordBitCap <= bits {
+		for i := 0; i < len(a.words); i++ {
+			a.words[i] = 0
+		}
+
+		return a.modified()
+	}
+
 	if 0 < a.bitCap {
 		k := bits / WordBitCap
 		if 0 < k {
-			copy(a.words[k:], a.words[:len(a.words)-k])
+			copy(a.words[:len(a.words)-k], a.words[k:])
 			for i := len(a.words) - k; i < len(a.words); i++ {
 				a.words[i] = 0
 			}
@@ -436,30 +826,97 @@ func (a *LMask) RSh(bits int) *LMask {
 
 		if r := bits - k*WordBitCap; 0 < r {
 			d := WordBitCap - r
-			h0 := a.words[len(a.words)-1] << d
-			a.words[len(a.words)-1] >>= r
-			for i := len(a.words) - 2; k <= i; i-- {
-				h1 := a.words[i] << d
-				a.words[i] = (a.words[i] >> r) | h0
-				h0 = h1
+			n := len(a.words) - k
+			for i := 0; i < n-1; i++ {
+				a.words[i] = (a.words[i] >> r) | (a.words[i+1] << d)
 			}
+
+			a.words[n-1] >>= r
 		}
 	}
 
 	return a.trim()
 }
 
+// Reverse reverses the order of the bits. That is, bit i is moved to
+// bit bitCap-1-i.
+func (a *LMask) Reverse() *LMask {
+	for i, j := 0, len(a.words)-1; i <= j; i, j = i+1, j-1 {
+		a.words[i], a.words[j] = bits.Reverse(a.words[j]), bits.Reverse(a.words[i])
+	}
+
+	// The bits were reversed within whole words.
+	return a.RSh(len(a.words)*WordBitCap - a.bitCap)
+}
+
+// ReverseBytes reverses the order of the bytes. ReverseBytes panics
+// with an error wrapping ErrInvalidBitCap if the bit capacity is not a
+// multiple of 8.
+func (a *LMask) ReverseBytes() *LMask {
+	if a.bitCap%8 != 0 {
+		panic(fmt.Errorf("%w: %d is not a multiple of 8", ErrInvalidBitCap, a.bitCap))
+	}
+
+	for i, j := 0, len(a.words)-1; i <= j; i, j = i+1, j-1 {
+		a.words[i], a.words[j] = bits.ReverseBytes(a.words[j]), bits.ReverseBytes(a.words[i])
+	}
+
+	// The bytes were reversed within whole words.
+	return a.RSh(len(a.words)*WordBitCap - a.bitCap)
+}
+
+// RotL rotates all bits to the left k bits within the bit capacity.
+// That is, each set bit i will be unset and bit (i+k) mod bitCap will be
+// set. A negative k rotates to the right.
+func (a *LMask) RotL(k int) *LMask {
+	if a.bitCap == 0 {
+		return a
+	}
+
+	if k %= a.bitCap; k < 0 {
+		k += a.bitCap
+	}
+
+	if k == 0 {
+		return a
+	}
+
+	if a.bitCap == WordBitCap {
+		a.words[0] = bits.RotateLeft(a.words[0], k)
+		return a.modified()
+	}
+
+	b := a.Copy().RSh(a.bitCap - k)
+	return a.LSh(k).Or(b)
+}
+
+// RotR rotates all bits to the right k bits within the bit capacity.
+// That is, each set bit i will be unset and bit (i-k) mod bitCap will
+// be set. A negative k rotates to the left.
+func (a *LMask) RotR(k int) *LMask {
+	if a.bitCap == 0 {
+		return a
+	}
+
+	return a.RotL(-(k % a.bitCap))
+}
+
 // Set sets the bits of b in a. Any bits already set in a will remain
 // set.
 func (a *LMask) Set(b *LMask) *LMask {
 	return a.Or(b)
 }
 
-// SetBit sets a bit in a.
+// SetBit sets a bit in a. If the bit is not on range [0, bitCap), then
+// SetBit panics with an error wrapping ErrBitOutOfRange.
 func (a *LMask) SetBit(bit int) *LMask {
+	if err := a.checkBit(bit); err != nil {
+		panic(err)
+	}
+
 	k := bit / WordBitCap
 	a.words[k] |= 1 << (bit - k*WordBitCap)
-	return a.trim()
+	return a.modified()
 }
 
 // SetBitCap sets the bit capacity. If the bit capacity is decreasing,
@@ -485,20 +942,41 @@ func (a *LMask) SetBitCap(bitCap int) *LMask {
 		words := make([]uint, n)
 		copy(words[:len(a.words)], a.words)
 		a.words = words
-		return a
+		return a.modified()
 	}
 
 	return a.trim()
 }
 
-// SetBits sets several bits.
+// SetBits sets several bits. If any bit is not on range [0, bitCap),
+// then SetBits panics with an error wrapping ErrBitOutOfRange and no
+// bits are set.
 func (a *LMask) SetBits(bits ...int) *LMask {
+	if err := a.checkBits(bits...); err != nil {
+		panic(err)
+	}
+
 	for i := 0; i < len(bits); i++ {
 		k := bits[i] / WordBitCap
 		a.words[k] |= 1 << (bits[i] - k*WordBitCap)
 	}
 
-	return a.trim()
+	return a.modified()
+}
+
+// SetRange sets the bits on range [lo, hi).
+func (a *LMask) SetRange(lo, hi int) *LMask {
+	if err := a.checkRange(lo, hi); err != nil {
+		panic(err)
+	}
+
+	lo, hi = clamp(lo, 0, a.bitCap), clamp(hi, 0, a.bitCap)
+
+	for k := lo / WordBitCap; k*WordBitCap < hi; k++ {
+		a.words[k] |= wordMask(k, lo, hi)
+	}
+
+	return a.modified()
 }
 
 // String returns the base-10 integer representation of a bitmask.
@@ -506,6 +984,41 @@ func (a *LMask) String() string {
 	return a.Fmt(10)
 }
 
+// UnmarshalBinary decodes data written by MarshalBinary into a
+// bitmask, restoring the exact bit capacity. An error wrapping
+// ErrInvalidEncoding is returned if the data is malformed.
+func (a *LMask) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: no data", ErrInvalidEncoding)
+	}
+
+	if data[0] != binaryVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidEncoding, data[0])
+	}
+
+	bitCap, k := binary.Uvarint(data[1:])
+	if k <= 0 {
+		return fmt.Errorf("%w: malformed bit capacity", ErrInvalidEncoding)
+	}
+
+	data = data[1+k:]
+	if n := uint64(len(data)); n*8 < bitCap || (bitCap+7)/8 < n {
+		return fmt.Errorf("%w: %d bytes for bit capacity %d", ErrInvalidEncoding, n, bitCap)
+	}
+
+	b := Zero(int(bitCap))
+	for i := 0; i < len(data); i++ {
+		b.words[i/wordBytes] |= uint(data[i]) << (8 * (i % wordBytes))
+	}
+
+	if r := int(bitCap) % 8; 0 < r && data[len(data)-1]>>r != 0 {
+		return fmt.Errorf("%w: bits set beyond bit capacity %d", ErrInvalidEncoding, bitCap)
+	}
+
+	*a = *b
+	return nil
+}
+
 // UnmarshalJSON decodes json-encoded text into a bitmask. If the text
 // is "null", no action is taken and no error is returned. Otherwise,
 // the text is assumed to be the base-10 integer representation of a
@@ -536,6 +1049,7 @@ func (a *LMask) UnmarshalText(b []byte) error {
 
 	a.bitCap = len(words) * WordBitCap
 	a.words = words
+	a.modified()
 	return nil
 }
 
@@ -569,7 +1083,66 @@ func min(a, b int) int {
 	return b
 }
 
+// bitsAt returns the n bits beginning at bit lo as an integer, where n
+// is at most the word bit capacity.
+func (a *LMask) bitsAt(lo, n int) uint {
+	k := lo / WordBitCap
+	r := lo - k*WordBitCap
+	w := a.words[k] >> r
+	if k+1 < len(a.words) && WordBitCap-r < n {
+		w |= a.words[k+1] << (WordBitCap - r)
+	}
+
+	return w & (1<<n - 1)
+}
+
+// checkBit returns an error wrapping ErrBitOutOfRange if a bit is not on
+// range [0, bitCap).
+func (a *LMask) checkBit(bit int) error {
+	if bit < 0 || a.bitCap <= bit {
+		return fmt.Errorf("%w: %d not on range [0, %d)", ErrBitOutOfRange, bit, a.bitCap)
+	}
+
+	return nil
+}
+
+// checkBits returns an error wrapping ErrBitOutOfRange for the first bit
+// not on range [0, bitCap).
+func (a *LMask) checkBits(bits ...int) error {
+	for i := 0; i < len(bits); i++ {
+		if err := a.checkBit(bits[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRange returns an error wrapping ErrBitOutOfRange if a non-empty
+// range [lo, hi) is not within [0, bitCap).
+func (a *LMask) checkRange(lo, hi int) error {
+	if lo < hi && (lo < 0 || a.bitCap < hi) {
+		return fmt.Errorf("%w: [%d, %d) not within [0, %d)", ErrBitOutOfRange, lo, hi, a.bitCap)
+	}
+
+	return nil
+}
+
+// uneqBitCaps returns an error indicating two bitmasks do not have the
+// same bit capacity.
+func uneqBitCaps(a, b *LMask) error {
+	return fmt.Errorf("%w: %d and %d", ErrUnequalBitCaps, a.bitCap, b.bitCap)
+}
+
+// modified discards any rank index, which is no longer valid once the
+// bits of a bitmask have been modified.
+func (a *LMask) modified() *LMask {
+	a.index = rankIndex{}
+	return a
+}
+
 // trim unsets any leading bits greater than the bitmask's bit capacity.
+// As trim follows every modification, it also discards any rank index.
 func (a *LMask) trim() *LMask {
 	if 0 < len(a.words) {
 		if r := a.bitCap - a.bitCap/WordBitCap*WordBitCap; 0 < r {
@@ -577,5 +1150,31 @@ func (a *LMask) trim() *LMask {
 		}
 	}
 
-	return a
+	return a.modified()
+}
+
+// wordMask returns the bits of the kth word that are on range [lo, hi).
+// The mask is zero if the range is empty.
+func wordMask(k, lo, hi int) uint {
+	m := uint(WordMax)
+	if r := lo - k*WordBitCap; 0 < r {
+		m &= WordMax << r
+	}
+
+	if r := (k+1)*WordBitCap - hi; 0 < r {
+		m &= WordMax >> r
+	}
+
+	return m
+}
+
+// orBitsAt sets the bits of v, which has at most n bits, in a list of
+// words beginning at bit lo.
+func orBitsAt(words []uint, lo int, v uint, n int) {
+	k := lo / WordBitCap
+	r := lo - k*WordBitCap
+	words[k] |= v << r
+	if WordBitCap < r+n {
+		words[k+1] |= v >> (WordBitCap - r)
+	}
 }
@@ -1,9 +1,13 @@
 package lmask
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"math/rand"
+	"slices"
 	"testing"
 )
 
@@ -256,6 +260,33 @@ func TestNot(t *testing.T) {
 	}
 }
 
+func TestNegatedLogic(t *testing.T) {
+	type testCase struct {
+		rec, exp *LMask
+	}
+
+	// Negating clears bits beyond the bit capacity, so Count and Equals
+	// see only bits on range [0, bitCap).
+	bitCap := WordBitCap + 5
+	tcs := []testCase{
+		{rec: Zero(bitCap).NAnd(Zero(bitCap)), exp: Max(bitCap)},
+		{rec: Zero(bitCap).NOr(Zero(bitCap)), exp: Max(bitCap)},
+		{rec: Zero(bitCap).XNOr(Zero(bitCap)), exp: Max(bitCap)},
+		{rec: One(bitCap).NAnd(Max(bitCap)), exp: Max(bitCap).ClrBit(0)},
+		{rec: One(bitCap).XNOr(Max(bitCap)), exp: One(bitCap)},
+	}
+
+	for _, tc := range tcs {
+		if !tc.exp.Equals(tc.rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", tc.exp, tc.rec)
+		}
+
+		if exp, rec := tc.exp.Count(), tc.rec.Count(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+	}
+}
+
 func TestOr(t *testing.T) {
 	type testCase struct {
 		a, b, exp *LMask
@@ -276,6 +307,251 @@ func TestOr(t *testing.T) {
 	}
 }
 
+func TestAllBackward(t *testing.T) {
+	type testCase struct {
+		a           *LMask
+		lo, hi      int
+		expAll      []int
+		expBackward []int
+	}
+
+	tcs := []testCase{
+		{
+			a:           Zero(4 * WordBitCap),
+			lo:          0,
+			hi:          4 * WordBitCap,
+			expAll:      []int{},
+			expBackward: []int{},
+		},
+		{
+			a:           FromBits(4*WordBitCap, 0, WordBitCap-1, WordBitCap, 4*WordBitCap-1),
+			lo:          0,
+			hi:          4 * WordBitCap,
+			expAll:      []int{0, WordBitCap - 1, WordBitCap, 4*WordBitCap - 1},
+			expBackward: []int{4*WordBitCap - 1, WordBitCap, WordBitCap - 1, 0},
+		},
+		{
+			a:           FromBits(4*WordBitCap, 0, WordBitCap-1, WordBitCap, 4*WordBitCap-1),
+			lo:          1,
+			hi:          4*WordBitCap - 1,
+			expAll:      []int{WordBitCap - 1, WordBitCap},
+			expBackward: []int{WordBitCap, WordBitCap - 1},
+		},
+		{
+			a:           FromBits(4*WordBitCap, 0, WordBitCap-1, WordBitCap, 4*WordBitCap-1),
+			lo:          WordBitCap,
+			hi:          WordBitCap,
+			expAll:      []int{},
+			expBackward: []int{},
+		},
+		{
+			a:           Max(4 * WordBitCap),
+			lo:          -5,
+			hi:          -10,
+			expAll:      []int{},
+			expBackward: []int{},
+		},
+		{
+			a:           Max(4 * WordBitCap),
+			lo:          4*WordBitCap + 5,
+			hi:          4*WordBitCap + 2,
+			expAll:      []int{},
+			expBackward: []int{},
+		},
+	}
+
+	for _, tc := range tcs {
+		if rec := slices.Collect(tc.a.AllRange(tc.lo, tc.hi)); !slices.Equal(tc.expAll, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", tc.expAll, rec)
+		}
+
+		if rec := slices.Collect(tc.a.BackwardRange(tc.lo, tc.hi)); !slices.Equal(tc.expBackward, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", tc.expBackward, rec)
+		}
+
+		if exp, rec := tc.a.Bits(), slices.Collect(tc.a.All()); !slices.Equal(exp, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		exp := tc.a.Bits()
+		slices.Reverse(exp)
+		if rec := slices.Collect(tc.a.Backward()); !slices.Equal(exp, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+	}
+
+	// Breaking out of a loop stops the iteration.
+	var n int
+	for range Max(4 * WordBitCap).All() {
+		if n++; n == 3 {
+			break
+		}
+	}
+
+	if n != 3 {
+		t.Errorf("\nexpected %d\nreceived %d\n", 3, n)
+	}
+
+	// A reused iterator keeps its range as the bit capacity changes.
+	var (
+		a        = Max(10)
+		all      = a.AllRange(2, 10)
+		backward = a.BackwardRange(2, 10)
+	)
+
+	a.SetBitCap(5)
+	if exp, rec := []int{2, 3, 4}, slices.Collect(all); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+
+	if exp, rec := []int{4, 3, 2}, slices.Collect(backward); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+
+	a.SetBitCap(10).SetRange(5, 10)
+	if exp, rec := []int{2, 3, 4, 5, 6, 7, 8, 9}, slices.Collect(all); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+
+	if exp, rec := []int{9, 8, 7, 6, 5, 4, 3, 2}, slices.Collect(backward); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+}
+
+func TestRange(t *testing.T) {
+	var (
+		bitCap = 3*WordBitCap + 5
+		a      = FromBits(bitCap, 0, 2, 3, WordBitCap-1, WordBitCap, WordBitCap+1, 2*WordBitCap+7, bitCap-1)
+		ends   = []int{-1, 0, 1, 3, WordBitCap - 1, WordBitCap, WordBitCap + 2, 2 * WordBitCap, bitCap - 1, bitCap, bitCap + 1}
+	)
+
+	for _, lo := range ends {
+		for _, hi := range ends {
+			if lo < hi && (lo < 0 || bitCap < hi) {
+				for name, f := range map[string]func(){
+					"AllInRange":    func() { a.AllInRange(lo, hi) },
+					"AllRange":      func() { a.AllRange(lo, hi) },
+					"AnyInRange":    func() { a.AnyInRange(lo, hi) },
+					"BackwardRange": func() { a.BackwardRange(lo, hi) },
+					"ClrRange":      func() { a.Copy().ClrRange(lo, hi) },
+					"CountRange":    func() { a.CountRange(lo, hi) },
+					"FlipRange":     func() { a.Copy().FlipRange(lo, hi) },
+					"SetRange":      func() { a.Copy().SetRange(lo, hi) },
+				} {
+					func() {
+						defer func() {
+							if err, _ := recover().(error); !errors.Is(err, ErrBitOutOfRange) {
+								t.Errorf("\nexpected %v from %s on [%d, %d)\nreceived %v\n", ErrBitOutOfRange, name, lo, hi, err)
+							}
+						}()
+
+						f()
+					}()
+				}
+
+				continue
+			}
+
+			var (
+				count                 int
+				setExp, clrExp, flExp = a.Copy(), a.Copy(), a.Copy()
+				anyExp, allExp        = false, true
+			)
+
+			for bit := lo; bit < hi; bit++ {
+				if a.MasksBit(bit) {
+					count++
+					anyExp = true
+					flExp.ClrBit(bit)
+				} else {
+					allExp = false
+					flExp.SetBit(bit)
+				}
+
+				setExp.SetBit(bit)
+				clrExp.ClrBit(bit)
+			}
+
+			if rec := a.CountRange(lo, hi); count != rec {
+				t.Errorf("\nexpected count %d on [%d, %d)\nreceived %d\n", count, lo, hi, rec)
+			}
+
+			if rec := a.AnyInRange(lo, hi); anyExp != rec {
+				t.Errorf("\nexpected any %t on [%d, %d)\nreceived %t\n", anyExp, lo, hi, rec)
+			}
+
+			if rec := a.AllInRange(lo, hi); allExp != rec {
+				t.Errorf("\nexpected all %t on [%d, %d)\nreceived %t\n", allExp, lo, hi, rec)
+			}
+
+			if rec := a.Copy().SetRange(lo, hi); !setExp.Equals(rec) {
+				t.Errorf("\nexpected %v on [%d, %d)\nreceived %v\n", setExp, lo, hi, rec)
+			}
+
+			if rec := a.Copy().ClrRange(lo, hi); !clrExp.Equals(rec) {
+				t.Errorf("\nexpected %v on [%d, %d)\nreceived %v\n", clrExp, lo, hi, rec)
+			}
+
+			if rec := a.Copy().FlipRange(lo, hi); !flExp.Equals(rec) {
+				t.Errorf("\nexpected %v on [%d, %d)\nreceived %v\n", flExp, lo, hi, rec)
+			}
+		}
+	}
+}
+
+func TestTryLogic(t *testing.T) {
+	type testCase struct {
+		name string
+		op   func(a, b *LMask) *LMask
+		try  func(a, b *LMask) (*LMask, error)
+	}
+
+	tcs := []testCase{
+		{name: "And", op: (*LMask).And, try: (*LMask).TryAnd},
+		{name: "AndNot", op: (*LMask).AndNot, try: (*LMask).TryAndNot},
+		{name: "NAnd", op: (*LMask).NAnd, try: (*LMask).TryNAnd},
+		{name: "NOr", op: (*LMask).NOr, try: (*LMask).TryNOr},
+		{name: "Or", op: (*LMask).Or, try: (*LMask).TryOr},
+		{name: "XNOr", op: (*LMask).XNOr, try: (*LMask).TryXNOr},
+		{name: "XOr", op: (*LMask).XOr, try: (*LMask).TryXOr},
+	}
+
+	for _, tc := range tcs {
+		var (
+			a = FromBits(2*WordBitCap, 0, WordBitCap, 2*WordBitCap-1)
+			b = FromBits(2*WordBitCap, 1, WordBitCap, WordBitCap+1)
+			c = FromBits(WordBitCap, 1)
+		)
+
+		if rec, err := tc.try(a.Copy(), b); err != nil {
+			t.Errorf("\n%s: unexpected error %v\n", tc.name, err)
+		} else if exp := tc.op(a.Copy(), b); !exp.Equals(rec) {
+			t.Errorf("\n%s: expected %v\nreceived %v\n", tc.name, exp, rec)
+		}
+
+		if _, err := tc.try(a.Copy(), c); !errors.Is(err, ErrUnequalBitCaps) {
+			t.Errorf("\n%s: expected %v\nreceived %v\n", tc.name, ErrUnequalBitCaps, err)
+		}
+
+		func() {
+			defer func() {
+				if err, _ := recover().(error); !errors.Is(err, ErrUnequalBitCaps) {
+					t.Errorf("\n%s: expected panic %v\nreceived %v\n", tc.name, ErrUnequalBitCaps, err)
+				}
+			}()
+
+			tc.op(a.Copy(), c)
+		}()
+
+		Widen(a, c)
+		if rec, err := tc.try(a.Copy(), c); err != nil {
+			t.Errorf("\n%s: unexpected error %v\n", tc.name, err)
+		} else if exp := tc.op(a.Copy(), FromBits(2*WordBitCap, 1)); !exp.Equals(rec) {
+			t.Errorf("\n%s: expected %v\nreceived %v\n", tc.name, exp, rec)
+		}
+	}
+}
+
 func TestBitCap(t *testing.T) {
 	type testCase struct {
 		bitCap int
@@ -426,6 +702,84 @@ func TestClrBits(t *testing.T) {
 	}
 }
 
+func TestBitOutOfRange(t *testing.T) {
+	type testCase struct {
+		name string
+		f    func(a *LMask, bit int) (*LMask, error)
+	}
+
+	tcs := []testCase{
+		{name: "ClrBit", f: (*LMask).TryClrBit},
+		{name: "ClrBits", f: func(a *LMask, bit int) (*LMask, error) { return a.TryClrBits(0, bit) }},
+		{name: "SetBit", f: (*LMask).TrySetBit},
+		{name: "SetBits", f: func(a *LMask, bit int) (*LMask, error) { return a.TrySetBits(0, bit) }},
+	}
+
+	for _, tc := range tcs {
+		for _, bitCap := range []int{0, 1, WordBitCap - 1, WordBitCap, WordBitCap + 1} {
+			for _, bit := range []int{-1, bitCap, bitCap + 1, 2 * WordBitCap} {
+				a := Max(bitCap)
+				exp := a.Copy()
+				if _, err := tc.f(a, bit); !errors.Is(err, ErrBitOutOfRange) {
+					t.Errorf("\n%s(%d): expected %v\nreceived %v\n", tc.name, bit, ErrBitOutOfRange, err)
+				}
+
+				if !exp.Equals(a) {
+					t.Errorf("\n%s(%d): expected %v\nreceived %v\n", tc.name, bit, exp, a)
+				}
+
+				if a.MasksBit(bit) {
+					t.Errorf("\nexpected bit %d to not be masked\n", bit)
+				}
+			}
+
+			if 0 < bitCap {
+				if _, err := tc.f(Zero(bitCap), bitCap-1); err != nil {
+					t.Errorf("\n%s(%d): unexpected error %v\n", tc.name, bitCap-1, err)
+				}
+			}
+		}
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrBitOutOfRange) {
+			t.Errorf("\nexpected panic %v\nreceived %v\n", ErrBitOutOfRange, err)
+		}
+	}()
+
+	Zero(WordBitCap - 1).SetBit(WordBitCap - 1)
+}
+
+func TestExtractDeposit(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, bitCap := range []int{1, 13, WordBitCap, WordBitCap + 1, 3*WordBitCap + 5} {
+		vals := []*LMask{Zero(bitCap), Max(bitCap), randomMask(r, bitCap), randomMask(r, bitCap), randomMask(r, bitCap).And(randomMask(r, bitCap))}
+		for _, a := range vals {
+			for _, m := range vals {
+				// Move bits one at a time.
+				expExt, expDep := Zero(bitCap), Zero(bitCap)
+				for i, bit := range slices.Collect(m.All()) {
+					if a.MasksBit(bit) {
+						expExt.SetBit(i)
+					}
+
+					if a.MasksBit(i) {
+						expDep.SetBit(bit)
+					}
+				}
+
+				if rec := a.Copy().Extract(m); !expExt.Equals(rec) {
+					t.Errorf("\nexpected %s\nreceived %s\n", expExt.Fmt(2), rec.Fmt(2))
+				}
+
+				if rec := a.Copy().Deposit(m); !expDep.Equals(rec) {
+					t.Errorf("\nexpected %s\nreceived %s\n", expDep.Fmt(2), rec.Fmt(2))
+				}
+			}
+		}
+	}
+}
+
 func TestJSON(t *testing.T) {
 	type testCase struct {
 		expLMask *LMask
@@ -487,6 +841,61 @@ func TestJSON(t *testing.T) {
 	}
 }
 
+func TestBinary(t *testing.T) {
+	type testCase struct {
+		a   *LMask
+		exp []byte
+	}
+
+	tcs := []testCase{
+		{a: Zero(0), exp: []byte{1, 0}},
+		{a: Zero(1), exp: []byte{1, 1, 0}},
+		{a: FromBits(10, 1, 3, 9), exp: []byte{1, 10, 0x0a, 0x02}},
+		{a: Max(16), exp: []byte{1, 16, 0xff, 0xff}},
+		{a: FromBits(200, 0, 199), exp: append(append([]byte{1, 200, 1, 1}, make([]byte, 23)...), 0x80)},
+		{a: FromBits(4*WordBitCap-1, 0, WordBitCap-1, WordBitCap, 4*WordBitCap-2)},
+		{a: Max(4*WordBitCap + 3)},
+	}
+
+	for _, tc := range tcs {
+		b, err := tc.a.MarshalBinary()
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if tc.exp != nil && !bytes.Equal(tc.exp, b) {
+			t.Errorf("\nexpected %x\nreceived %x\n", tc.exp, b)
+		}
+
+		var rec LMask
+		if err := rec.UnmarshalBinary(b); err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if !tc.a.Equals(&rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", tc.a, &rec)
+		}
+	}
+
+	invalid := [][]byte{
+		nil,
+		{2, 0},
+		{1},
+		{1, 0x80},
+		{1, 9, 0xff},
+		{1, 8, 0xff, 0x00},
+		{1, 10, 0xff, 0x04},
+	}
+
+	for _, b := range invalid {
+		if err := new(LMask).UnmarshalBinary(b); !errors.Is(err, ErrInvalidEncoding) {
+			t.Errorf("\nexpected %v for %x\nreceived %v\n", ErrInvalidEncoding, b, err)
+		}
+	}
+}
+
 func TestBitLen(t *testing.T) {
 	type testCase struct {
 		a   *LMask
@@ -622,6 +1031,27 @@ func TestLRSh(t *testing.T) {
 			expLeft:  FromBits(4*WordBitCap, 2*WordBitCap, 3*WordBitCap-1, 3*WordBitCap, 4*WordBitCap-1),
 			expRight: FromBits(4*WordBitCap, 0, WordBitCap-1, WordBitCap, 2*WordBitCap-1),
 		},
+		{
+			a:        FromBits(3*WordBitCap+5, 1, WordBitCap+2),
+			left:     WordBitCap + 3,
+			right:    3,
+			expLeft:  FromBits(3*WordBitCap+5, WordBitCap+4, 2*WordBitCap+5),
+			expRight: FromBits(3*WordBitCap+5, WordBitCap-1),
+		},
+		{
+			a:        FromBits(3*WordBitCap+5, 1, WordBitCap+2, 3*WordBitCap+4),
+			left:     2*WordBitCap + 4,
+			right:    WordBitCap + 1,
+			expLeft:  FromBits(3*WordBitCap+5, 2*WordBitCap+5),
+			expRight: FromBits(3*WordBitCap+5, 1, 2*WordBitCap+3),
+		},
+		{
+			a:        Max(3*WordBitCap + 5),
+			left:     3*WordBitCap + 5,
+			right:    3*WordBitCap + 5,
+			expLeft:  Zero(3*WordBitCap + 5),
+			expRight: Zero(3*WordBitCap + 5),
+		},
 	}
 
 	for _, tc := range tcs {
@@ -635,6 +1065,58 @@ func TestLRSh(t *testing.T) {
 	}
 }
 
+func TestRotReverse(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, bitCap := range []int{1, 8, 13, WordBitCap - 1, WordBitCap, WordBitCap + 8, 3*WordBitCap + 5} {
+		a := randomMask(r, bitCap)
+		for _, k := range []int{-bitCap - 1, -1, 0, 1, 3, bitCap - 1, bitCap, 2*bitCap + 1, WordBitCap + 1} {
+			expL, expR := Zero(bitCap), Zero(bitCap)
+			for bit := range a.All() {
+				expL.SetBit(((bit+k)%bitCap + bitCap) % bitCap)
+				expR.SetBit(((bit-k)%bitCap + bitCap) % bitCap)
+			}
+
+			if rec := a.Copy().RotL(k); !expL.Equals(rec) {
+				t.Errorf("\nexpected %s\nreceived %s\n", expL.Fmt(2), rec.Fmt(2))
+			}
+
+			if rec := a.Copy().RotR(k); !expR.Equals(rec) {
+				t.Errorf("\nexpected %s\nreceived %s\n", expR.Fmt(2), rec.Fmt(2))
+			}
+		}
+
+		exp := Zero(bitCap)
+		for bit := range a.All() {
+			exp.SetBit(bitCap - 1 - bit)
+		}
+
+		if rec := a.Copy().Reverse(); !exp.Equals(rec) {
+			t.Errorf("\nexpected %s\nreceived %s\n", exp.Fmt(2), rec.Fmt(2))
+		}
+
+		if bitCap%8 == 0 {
+			exp := Zero(bitCap)
+			for bit := range a.All() {
+				exp.SetBit(bitCap - 8 - bit/8*8 + bit%8)
+			}
+
+			if rec := a.Copy().ReverseBytes(); !exp.Equals(rec) {
+				t.Errorf("\nexpected %s\nreceived %s\n", exp.Fmt(2), rec.Fmt(2))
+			}
+		} else {
+			func() {
+				defer func() {
+					if err, _ := recover().(error); !errors.Is(err, ErrInvalidBitCap) {
+						t.Errorf("\nexpected %v for bit capacity %d\nreceived %v\n", ErrInvalidBitCap, bitCap, err)
+					}
+				}()
+
+				a.Copy().ReverseBytes()
+			}()
+		}
+	}
+}
+
 func TestNextPrevBit(t *testing.T) {
 	type testCase struct {
 		a *LMask
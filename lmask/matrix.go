@@ -0,0 +1,431 @@
+package lmask
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+const (
+	// fourRussiansBits is the number of rows of the right-hand matrix
+	// combined into each lookup table by the Four Russians method.
+	fourRussiansBits = 8
+
+	// fourRussiansMinRows is the fewest rows of the left-hand matrix for
+	// which building lookup tables is cheaper than adding rows directly.
+	fourRussiansMinRows = 64
+)
+
+// BitMatrix is a matrix over GF(2), the field of two elements in which
+// addition is exclusive or and multiplication is and. Each row is a
+// bitmask whose bit capacity is the number of columns, so adding one row
+// to another is XOr.
+type BitMatrix struct {
+	rows, cols int
+	data       []*LMask
+}
+
+// --------------------------------------------------------------------
+// Constructors
+// --------------------------------------------------------------------
+
+// NewBitMatrix returns a matrix of zeros.
+func NewBitMatrix(rows, cols int) *BitMatrix {
+	m := &BitMatrix{rows: rows, cols: cols, data: make([]*LMask, rows)}
+	for i := 0; i < rows; i++ {
+		m.data[i] = Zero(cols)
+	}
+
+	return m
+}
+
+// BitMatrixFromRows returns a matrix having copies of the given rows.
+// The number of columns is the bit capacity of the rows, which must be
+// equal.
+func BitMatrixFromRows(rows ...*LMask) *BitMatrix {
+	m := &BitMatrix{rows: len(rows), data: make([]*LMask, len(rows))}
+	for i := 0; i < len(rows); i++ {
+		if rows[i].bitCap != rows[0].bitCap {
+			panic(uneqBitCaps(rows[0], rows[i]))
+		}
+
+		m.data[i] = rows[i].Copy()
+	}
+
+	if 0 < len(rows) {
+		m.cols = rows[0].bitCap
+	}
+
+	return m
+}
+
+// IdentityBitMatrix returns the n-by-n identity matrix.
+func IdentityBitMatrix(n int) *BitMatrix {
+	m := NewBitMatrix(n, n)
+	for i := 0; i < n; i++ {
+		m.data[i].SetBit(i)
+	}
+
+	return m
+}
+
+// --------------------------------------------------------------------
+// Access functionality
+// --------------------------------------------------------------------
+
+// At determines if the entry in row i and column j is one.
+func (m *BitMatrix) At(i, j int) bool {
+	return m.data[i].MasksBit(j)
+}
+
+// Col returns a copy of column j. Its bit capacity is the number of
+// rows.
+func (m *BitMatrix) Col(j int) *LMask {
+	c := Zero(m.rows)
+	for i := 0; i < m.rows; i++ {
+		if m.data[i].MasksBit(j) {
+			c.SetBit(i)
+		}
+	}
+
+	return c
+}
+
+// Cols returns the number of columns.
+func (m *BitMatrix) Cols() int {
+	return m.cols
+}
+
+// Copy returns a copy of a matrix.
+func (m *BitMatrix) Copy() *BitMatrix {
+	return BitMatrixFromRows(m.data...).reshape(m.rows, m.cols)
+}
+
+// Equals determines if two matrices have the same dimensions and
+// entries.
+func (m *BitMatrix) Equals(n *BitMatrix) bool {
+	if m.rows != n.rows || m.cols != n.cols {
+		return false
+	}
+
+	for i := 0; i < m.rows; i++ {
+		if !m.data[i].Equals(n.data[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Row returns a copy of row i. Its bit capacity is the number of
+// columns.
+func (m *BitMatrix) Row(i int) *LMask {
+	return m.data[i].Copy()
+}
+
+// Rows returns the number of rows.
+func (m *BitMatrix) Rows() int {
+	return m.rows
+}
+
+// Set sets the entry in row i and column j to one if v is true and to
+// zero otherwise.
+func (m *BitMatrix) Set(i, j int, v bool) *BitMatrix {
+	if v {
+		m.data[i].SetBit(j)
+	} else {
+		m.data[i].ClrBit(j)
+	}
+
+	return m
+}
+
+// SetCol sets column j to a copy of c, whose bit capacity must be the
+// number of rows.
+func (m *BitMatrix) SetCol(j int, c *LMask) *BitMatrix {
+	if c.bitCap != m.rows {
+		panic(fmt.Errorf("%w: %d rows and %d", ErrUnequalBitCaps, m.rows, c.bitCap))
+	}
+
+	for i := 0; i < m.rows; i++ {
+		m.Set(i, j, c.MasksBit(i))
+	}
+
+	return m
+}
+
+// SetRow sets row i to a copy of r, whose bit capacity must be the
+// number of columns.
+func (m *BitMatrix) SetRow(i int, r *LMask) *BitMatrix {
+	if r.bitCap != m.cols {
+		panic(fmt.Errorf("%w: %d columns and %d", ErrUnequalBitCaps, m.cols, r.bitCap))
+	}
+
+	copy(m.data[i].words, r.words)
+	m.data[i].modified()
+	return m
+}
+
+// String returns each row as a line of zeros and ones, beginning with
+// column zero.
+func (m *BitMatrix) String() string {
+	var sb strings.Builder
+	for i := 0; i < m.rows; i++ {
+		if 0 < i {
+			sb.WriteByte('\n')
+		}
+
+		for j := 0; j < m.cols; j++ {
+			if m.data[i].MasksBit(j) {
+				sb.WriteByte('1')
+			} else {
+				sb.WriteByte('0')
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// --------------------------------------------------------------------
+// Algebraic functionality
+// --------------------------------------------------------------------
+
+// Inverse returns the inverse of a square matrix. If the matrix is not
+// square or is singular, then nil and false are returned.
+func (m *BitMatrix) Inverse() (*BitMatrix, bool) {
+	if m.rows != m.cols {
+		return nil, false
+	}
+
+	// Reduce [m | I] to [I | m^-1].
+	aug := m.augment(IdentityBitMatrix(m.rows))
+	if len(aug.rref(m.cols)) < m.rows {
+		return nil, false
+	}
+
+	inv := NewBitMatrix(m.rows, m.cols)
+	for i := 0; i < m.rows; i++ {
+		inv.data[i] = aug.data[i].RSh(m.cols).SetBitCap(m.cols)
+	}
+
+	return inv, true
+}
+
+// Mul returns the product of m and n. The number of columns of m must
+// equal the number of rows of n. Large products are computed by the
+// Four Russians method.
+func (m *BitMatrix) Mul(n *BitMatrix) *BitMatrix {
+	if m.cols != n.rows {
+		panic(fmt.Errorf("%w: %d columns and %d rows", ErrUnequalBitCaps, m.cols, n.rows))
+	}
+
+	if m.rows < fourRussiansMinRows {
+		return m.mulRows(n)
+	}
+
+	return m.mulFourRussians(n)
+}
+
+// MulVec returns the product of m and a column vector x, whose bit
+// capacity must be the number of columns. The product's bit capacity is
+// the number of rows.
+func (m *BitMatrix) MulVec(x *LMask) *LMask {
+	if x.bitCap != m.cols {
+		panic(fmt.Errorf("%w: %d columns and %d", ErrUnequalBitCaps, m.cols, x.bitCap))
+	}
+
+	y := Zero(m.rows)
+	for i := 0; i < m.rows; i++ {
+		if dot(m.data[i], x) {
+			y.SetBit(i)
+		}
+	}
+
+	return y
+}
+
+// Nullspace returns a basis of the vectors x such that m times x is
+// zero. Each vector's bit capacity is the number of columns. The basis
+// is empty if m has full column rank.
+func (m *BitMatrix) Nullspace() []*LMask {
+	r := m.Copy()
+	pivots := r.rref(r.cols)
+
+	var basis []*LMask
+	for f, p := 0, 0; f < r.cols; f++ {
+		if p < len(pivots) && pivots[p] == f {
+			p++
+			continue
+		}
+
+		// Set free column f and solve for each pivot column.
+		x := FromBits(r.cols, f)
+		for i := 0; i < len(pivots); i++ {
+			if r.data[i].MasksBit(f) {
+				x.SetBit(pivots[i])
+			}
+		}
+
+		basis = append(basis, x)
+	}
+
+	return basis
+}
+
+// Rank returns the dimension of the row space.
+func (m *BitMatrix) Rank() int {
+	return len(m.Copy().rref(m.cols))
+}
+
+// RREF reduces m to reduced row echelon form by Gaussian elimination.
+func (m *BitMatrix) RREF() *BitMatrix {
+	m.rref(m.cols)
+	return m
+}
+
+// Solve returns a vector x such that m times x is b, whose bit capacity
+// must be the number of rows. Free variables are zero. If no solution
+// exists, then nil and false are returned.
+func (m *BitMatrix) Solve(b *LMask) (*LMask, bool) {
+	if b.bitCap != m.rows {
+		panic(fmt.Errorf("%w: %d rows and %d", ErrUnequalBitCaps, m.rows, b.bitCap))
+	}
+
+	// Reduce [m | b]. A row without a pivot that is one in the last
+	// column means 0 = 1.
+	aug := m.augment(BitMatrixFromRows(b).Transpose())
+	pivots := aug.rref(m.cols)
+	for i := len(pivots); i < m.rows; i++ {
+		if aug.data[i].MasksBit(m.cols) {
+			return nil, false
+		}
+	}
+
+	x := Zero(m.cols)
+	for i := 0; i < len(pivots); i++ {
+		if aug.data[i].MasksBit(m.cols) {
+			x.SetBit(pivots[i])
+		}
+	}
+
+	return x, true
+}
+
+// Transpose returns the transpose of m.
+func (m *BitMatrix) Transpose() *BitMatrix {
+	t := NewBitMatrix(m.cols, m.rows)
+	for i := 0; i < m.rows; i++ {
+		for j := range m.data[i].All() {
+			t.data[j].SetBit(i)
+		}
+	}
+
+	return t
+}
+
+// --------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------
+
+// augment returns the matrix [m | n] having the columns of n appended to
+// the columns of m. Both must have the same number of rows.
+func (m *BitMatrix) augment(n *BitMatrix) *BitMatrix {
+	a := NewBitMatrix(m.rows, m.cols+n.cols)
+	for i := 0; i < m.rows; i++ {
+		r := n.data[i].Copy().SetBitCap(a.cols).LSh(m.cols)
+		a.data[i] = r.Or(m.data[i].Copy().SetBitCap(a.cols))
+	}
+
+	return a
+}
+
+// mulFourRussians returns the product of m and n. The rows of n are
+// taken fourRussiansBits at a time and every sum of them is tabulated,
+// so each row of m adds one table entry per group rather than one row
+// of n per set bit.
+func (m *BitMatrix) mulFourRussians(n *BitMatrix) *BitMatrix {
+	p := NewBitMatrix(m.rows, n.cols)
+	table := make([]*LMask, 1<<fourRussiansBits)
+	for g := 0; g < len(table); g++ {
+		table[g] = Zero(n.cols)
+	}
+
+	for lo := 0; lo < m.cols; lo += fourRussiansBits {
+		k := min(fourRussiansBits, m.cols-lo)
+
+		// Each sum is a smaller sum plus one more row.
+		for g := 1; g < 1<<k; g++ {
+			copy(table[g].words, table[g&(g-1)].words)
+			table[g].XOr(n.data[lo+bits.TrailingZeros(uint(g))])
+		}
+
+		for i := 0; i < m.rows; i++ {
+			if g := m.data[i].bitsAt(lo, k); g != 0 {
+				p.data[i].XOr(table[g])
+			}
+		}
+	}
+
+	return p
+}
+
+// mulRows returns the product of m and n by adding row j of n to row i
+// of the product for each entry of m in row i and column j that is one.
+func (m *BitMatrix) mulRows(n *BitMatrix) *BitMatrix {
+	p := NewBitMatrix(m.rows, n.cols)
+	for i := 0; i < m.rows; i++ {
+		for j := range m.data[i].All() {
+			p.data[i].XOr(n.data[j])
+		}
+	}
+
+	return p
+}
+
+// reshape sets the dimensions of a matrix, which is needed when it has
+// no rows to infer the number of columns from.
+func (m *BitMatrix) reshape(rows, cols int) *BitMatrix {
+	m.rows, m.cols = rows, cols
+	return m
+}
+
+// rref reduces m to reduced row echelon form, choosing pivots only from
+// the first n columns, and returns the pivot columns in order. The ith
+// pivot is in row i, so the rows without a pivot are zero in the first
+// n columns.
+func (m *BitMatrix) rref(n int) []int {
+	var pivots []int
+	for c := 0; c < n && len(pivots) < m.rows; c++ {
+		r := len(pivots)
+		p := r
+		for p < m.rows && !m.data[p].MasksBit(c) {
+			p++
+		}
+
+		if p == m.rows {
+			continue
+		}
+
+		m.data[r], m.data[p] = m.data[p], m.data[r]
+		for i := 0; i < m.rows; i++ {
+			if i != r && m.data[i].MasksBit(c) {
+				m.data[i].XOr(m.data[r])
+			}
+		}
+
+		pivots = append(pivots, c)
+	}
+
+	return pivots
+}
+
+// dot determines if an odd number of bits are set in both a and b.
+func dot(a, b *LMask) bool {
+	var w uint
+	for i := 0; i < len(a.words); i++ {
+		w ^= a.words[i] & b.words[i]
+	}
+
+	return bits.OnesCount(w)&1 == 1
+}
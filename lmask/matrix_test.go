@@ -0,0 +1,149 @@
+package lmask
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBitMatrixAccess(t *testing.T) {
+	m := BitMatrixFromRows(FromBits(3, 0, 2), FromBits(3, 1))
+	if exp, rec := "101\n010", m.String(); exp != rec {
+		t.Errorf("\nexpected\n%s\nreceived\n%s\n", exp, rec)
+	}
+
+	if exp, rec := FromBits(2, 0), m.Col(2); !exp.Equals(rec) {
+		t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+	}
+
+	m.SetCol(0, FromBits(2, 1)).SetRow(0, m.Row(0).SetBit(1)).Set(1, 2, true)
+	if exp, rec := "011\n111", m.String(); exp != rec {
+		t.Errorf("\nexpected\n%s\nreceived\n%s\n", exp, rec)
+	}
+
+	// Rows are copied in and out.
+	m.Row(0).ClrBit(1)
+	if !m.At(0, 1) {
+		t.Errorf("\nexpected row 0 to be unchanged\n")
+	}
+
+	if exp, rec := "01\n11\n11", m.Transpose().String(); exp != rec {
+		t.Errorf("\nexpected\n%s\nreceived\n%s\n", exp, rec)
+	}
+}
+
+func TestBitMatrixMul(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, dims := range [][3]int{{0, 0, 0}, {1, 1, 1}, {3, 5, 2}, {70, 3*WordBitCap + 5, 9}, {200, 65, WordBitCap + 1}} {
+		a, b := randomBitMatrix(r, dims[0], dims[1]), randomBitMatrix(r, dims[1], dims[2])
+
+		// Multiply entry by entry.
+		exp := NewBitMatrix(dims[0], dims[2])
+		for i := 0; i < dims[0]; i++ {
+			for j := 0; j < dims[2]; j++ {
+				var v bool
+				for k := 0; k < dims[1]; k++ {
+					v = v != (a.At(i, k) && b.At(k, j))
+				}
+
+				exp.Set(i, j, v)
+			}
+		}
+
+		if rec := a.Mul(b); !exp.Equals(rec) {
+			t.Errorf("\nexpected\n%v\nreceived\n%v\n", exp, rec)
+		}
+
+		if rec := a.mulRows(b); !exp.Equals(rec) {
+			t.Errorf("\nexpected\n%v\nreceived\n%v\n", exp, rec)
+		}
+
+		if rec := a.mulFourRussians(b); !exp.Equals(rec) {
+			t.Errorf("\nexpected\n%v\nreceived\n%v\n", exp, rec)
+		}
+
+		for j := 0; j < dims[2]; j++ {
+			if exp, rec := exp.Col(j), a.MulVec(b.Col(j)); !exp.Equals(rec) {
+				t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+			}
+		}
+
+		if rec := b.Transpose().Mul(a.Transpose()).Transpose(); !exp.Equals(rec) {
+			t.Errorf("\nexpected\n%v\nreceived\n%v\n", exp, rec)
+		}
+	}
+}
+
+func TestBitMatrixElimination(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, dims := range [][2]int{{1, 1}, {4, 4}, {5, 3}, {3, 5}, {WordBitCap + 3, WordBitCap + 3}, {40, 100}} {
+		for n := 0; n < 10; n++ {
+			a := randomBitMatrix(r, dims[0], dims[1])
+			if n%3 == 0 && 1 < dims[0] {
+				// Make a row dependent on the others.
+				a.SetRow(0, a.Row(1).XOr(a.Row(dims[0]-1)))
+			}
+
+			rank := a.Rank()
+			basis := a.Nullspace()
+			if exp, rec := dims[1]-rank, len(basis); exp != rec {
+				t.Errorf("\nexpected nullity %d\nreceived %d\n", exp, rec)
+			}
+
+			for _, x := range basis {
+				if rec := a.MulVec(x); rec.Count() != 0 {
+					t.Errorf("\nexpected zero\nreceived %v\n", rec)
+				}
+			}
+
+			// A reachable right-hand side is solvable.
+			b := a.MulVec(randomMask(r, dims[1]))
+			x, ok := a.Solve(b)
+			if !ok || !b.Equals(a.MulVec(x)) {
+				t.Errorf("\nexpected a solution to\n%v\nx = %v\n", a, b)
+			}
+
+			if rank < dims[0] {
+				// Some unit vector is outside the column space.
+				for c := 0; c < dims[0]; c++ {
+					if _, ok := a.Solve(FromBits(dims[0], c)); !ok {
+						break
+					}
+
+					if c == dims[0]-1 {
+						t.Errorf("\nexpected an inconsistent system for\n%v\n", a)
+					}
+				}
+			}
+
+			inv, ok := a.Inverse()
+			if exp := dims[0] == dims[1] && rank == dims[0]; exp != ok {
+				t.Errorf("\nexpected invertible %t\nreceived %t\n", exp, ok)
+			}
+
+			if ok {
+				if exp := IdentityBitMatrix(dims[0]); !exp.Equals(a.Mul(inv)) || !exp.Equals(inv.Mul(a)) {
+					t.Errorf("\nexpected inverse of\n%v\nreceived\n%v\n", a, inv)
+				}
+			}
+		}
+	}
+
+	if exp, rec := 0, NewBitMatrix(3, 4).Rank(); exp != rec {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+
+	if exp, rec := "100\n011\n000", BitMatrixFromRows(FromBits(3, 0, 1, 2), FromBits(3, 1, 2), FromBits(3, 0)).RREF().String(); exp != rec {
+		t.Errorf("\nexpected\n%s\nreceived\n%s\n", exp, rec)
+	}
+}
+
+// randomBitMatrix returns a matrix with each entry one with probability
+// one half.
+func randomBitMatrix(r *rand.Rand, rows, cols int) *BitMatrix {
+	m := NewBitMatrix(rows, cols)
+	for i := 0; i < rows; i++ {
+		m.SetRow(i, randomMask(r, cols))
+	}
+
+	return m
+}
@@ -0,0 +1,145 @@
+package lmask
+
+import (
+	"math/bits"
+	"sort"
+)
+
+const (
+	// blockBitCap is the number of bits counted by each block of a rank
+	// index.
+	blockBitCap = 512
+
+	// blockWords is the number of words in a block.
+	blockWords = blockBitCap / WordBitCap
+
+	// superBlockBlocks is the number of blocks in a superblock. The
+	// number of bits counted within a superblock must fit in a uint16.
+	superBlockBlocks = 1 << 16 / blockBitCap
+)
+
+// rankIndex is an auxiliary index of the number of bits set in a
+// bitmask before each superblock and block. Block counts are relative
+// to their superblock. The zero value is an index that has not been
+// built.
+type rankIndex struct {
+	count  int
+	supers []int
+	blocks []uint16
+}
+
+// newRankIndex returns a rank index over a list of words. There is one
+// more block than needed to cover the words so that the bit capacity
+// may be ranked without bounds checks. As a result, a built index
+// always has at least one block.
+func newRankIndex(words []uint) rankIndex {
+	n := len(words)/blockWords + 1
+	x := rankIndex{
+		supers: make([]int, (n-1)/superBlockBlocks+1),
+		blocks: make([]uint16, n),
+	}
+
+	for b := 0; b < n; b++ {
+		s := b / superBlockBlocks
+		if b == s*superBlockBlocks {
+			x.supers[s] = x.count
+		}
+
+		x.blocks[b] = uint16(x.count - x.supers[s])
+		for i := b * blockWords; i < (b+1)*blockWords && i < len(words); i++ {
+			x.count += bits.OnesCount(words[i])
+		}
+	}
+
+	return x
+}
+
+// BuildRank builds an index of bit counts, after which Rank takes
+// constant time and Select takes logarithmic time until the bitmask is
+// next modified. Without the index, both take linear time. Like any
+// modification, BuildRank must not be called concurrently with other
+// methods on the same bitmask. Rank and Select only read the index, so
+// they are safe to call concurrently once it is built.
+func (a *LMask) BuildRank() *LMask {
+	if len(a.index.blocks) == 0 {
+		a.index = newRankIndex(a.words)
+	}
+
+	return a
+}
+
+// Rank returns the number of bits set below a given bit.
+func (a *LMask) Rank(bit int) int {
+	var (
+		x = &a.index
+		r int
+		i int
+	)
+
+	bit = clamp(bit, 0, a.bitCap)
+	if 0 < len(x.blocks) {
+		b := bit / blockBitCap
+		r, i = x.supers[b/superBlockBlocks]+int(x.blocks[b]), b*blockWords
+	}
+
+	k := bit / WordBitCap
+	for ; i < k; i++ {
+		r += bits.OnesCount(a.words[i])
+	}
+
+	if m := bit - k*WordBitCap; 0 < m {
+		r += bits.OnesCount(a.words[k] << (WordBitCap - m))
+	}
+
+	return r
+}
+
+// Select returns the position of the kth set bit, counting from zero.
+// That is, Select is the inverse of Rank such that a.Rank(a.Select(k))
+// is k. If k is negative or not less than the number of bits set, then
+// the bit capacity is returned.
+func (a *LMask) Select(k int) int {
+	var (
+		x = &a.index
+		i int
+	)
+
+	if k < 0 {
+		return a.bitCap
+	}
+
+	if 0 < len(x.blocks) {
+		if x.count <= k {
+			return a.bitCap
+		}
+
+		s := sort.Search(len(x.supers), func(s int) bool { return k < x.supers[s] }) - 1
+		k -= x.supers[s]
+
+		lo, hi := s*superBlockBlocks, min((s+1)*superBlockBlocks, len(x.blocks))
+		b := lo + sort.Search(hi-lo, func(i int) bool { return k < int(x.blocks[lo+i]) }) - 1
+		k -= int(x.blocks[b])
+		i = b * blockWords
+	}
+
+	for ; i < len(a.words); i++ {
+		c := bits.OnesCount(a.words[i])
+		if k < c {
+			return i*WordBitCap + selectWord(a.words[i], k)
+		}
+
+		k -= c
+	}
+
+	return a.bitCap
+}
+
+// selectWord returns the position of the kth set bit in a word. The
+// word is assumed to have more than k bits set.
+func selectWord(w uint, k int) int {
+	for ; 0 < k; k-- {
+		w &= w - 1
+	}
+
+	return bits.TrailingZeros(w)
+}
@@ -0,0 +1,141 @@
+package lmask
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestRankSelect(t *testing.T) {
+	type testCase struct {
+		a *LMask
+	}
+
+	var (
+		r      = rand.New(rand.NewSource(0))
+		bitCap = 3<<16 + 5
+		random = Zero(bitCap)
+	)
+
+	for i := 0; i < bitCap/16; i++ {
+		random.SetBit(r.Intn(bitCap))
+	}
+
+	tcs := []testCase{
+		{a: Zero(0)},
+		{a: Zero(bitCap)},
+		{a: One(bitCap)},
+		{a: Max(bitCap)},
+		{a: FromBits(bitCap, 0, WordBitCap-1, blockBitCap, 1<<16-1, 1<<16, bitCap-1)},
+		{a: random},
+	}
+
+	for _, tc := range tcs {
+		testRankSelect(t, tc.a)
+	}
+
+	// Modifications invalidate the index.
+	a := FromBits(bitCap, 1, 1<<16+1)
+	if rec := a.BuildRank().Rank(bitCap); rec != 2 {
+		t.Errorf("\nexpected %d\nreceived %d\n", 2, rec)
+	}
+
+	a.SetBit(0).ClrBit(1<<16 + 1).SetBit(bitCap - 1)
+	testRankSelect(t, a)
+
+	a.Not()
+	testRankSelect(t, a)
+
+	a.LSh(3).And(random)
+	testRankSelect(t, a)
+
+	a.SetBitCap(2 * bitCap).SetBit(2*bitCap - 1)
+	testRankSelect(t, a)
+}
+
+func TestRankSelectConcurrent(t *testing.T) {
+	var (
+		r      = rand.New(rand.NewSource(0))
+		bitCap = 1<<16 + 5
+		masks  = []*LMask{Zero(bitCap), Zero(bitCap)}
+	)
+
+	for i := 0; i < bitCap/16; i++ {
+		bit := r.Intn(bitCap)
+		masks[0].SetBit(bit)
+		masks[1].SetBit(bit)
+	}
+
+	// Rank and Select only read a bitmask, with or without an index.
+	masks[1].BuildRank()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		for _, a := range masks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := 0; k < 100; k++ {
+					if bit := a.Select(k); a.Rank(bit) != k {
+						t.Errorf("\nexpected rank %d\nreceived %d\n", k, a.Rank(bit))
+					}
+				}
+			}()
+		}
+	}
+
+	wg.Wait()
+}
+
+// testRankSelect compares Rank and Select against a linear scan, both
+// without and with an index. Without an index each query takes linear
+// time, so only every 97th bit is queried.
+func testRankSelect(t *testing.T, a *LMask) {
+	testRankSelectEvery(t, a.Copy(), 97)
+	testRankSelectEvery(t, a.BuildRank(), 1)
+}
+
+// testRankSelectEvery compares Rank and Select against a linear scan at
+// every nth bit and at the bit capacity.
+func testRankSelectEvery(t *testing.T, a *LMask, n int) {
+	var (
+		bitCap = a.BitCap()
+		rank   int
+	)
+
+	for bit := 0; bit <= bitCap; bit++ {
+		query := bit%n == 0 || bit == bitCap
+		if query {
+			if rec := a.Rank(bit); rank != rec {
+				t.Errorf("\nexpected rank %d at bit %d\nreceived %d\n", rank, bit, rec)
+				return
+			}
+		}
+
+		if bit < bitCap && a.MasksBit(bit) {
+			if query {
+				if rec := a.Select(rank); bit != rec {
+					t.Errorf("\nexpected select %d to be %d\nreceived %d\n", rank, bit, rec)
+					return
+				}
+			}
+
+			rank++
+		}
+	}
+
+	if rec := a.Select(rank); bitCap != rec {
+		t.Errorf("\nexpected %d\nreceived %d\n", bitCap, rec)
+	}
+
+	if rec := a.Select(-1); bitCap != rec {
+		t.Errorf("\nexpected %d\nreceived %d\n", bitCap, rec)
+	}
+}
+
+func BenchmarkRank(b *testing.B) {
+	a := Max(1 << 20).BuildRank()
+	for i := 0; i < b.N; i++ {
+		a.Rank(i & (1<<20 - 1))
+	}
+}
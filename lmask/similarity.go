@@ -0,0 +1,131 @@
+package lmask
+
+import "math/bits"
+
+// Dice returns the Sørensen–Dice coefficient of a and b, which is twice
+// the number of bits set in both divided by the sum of the numbers of
+// bits set in each. If no bits are set in either, then it is one. The
+// bit capacities of a and b must be equal.
+func (a *LMask) Dice(b *LMask) float64 {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	var and, sum int
+	for i := 0; i < len(a.words); i++ {
+		and += bits.OnesCount(a.words[i] & b.words[i])
+		sum += bits.OnesCount(a.words[i]) + bits.OnesCount(b.words[i])
+	}
+
+	if sum == 0 {
+		return 1
+	}
+
+	return float64(2*and) / float64(sum)
+}
+
+// HammingDistance returns the number of bits set in exactly one of a
+// and b. The bit capacities of a and b must be equal.
+func (a *LMask) HammingDistance(b *LMask) int {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	var c int
+	for i := 0; i < len(a.words); i++ {
+		c += bits.OnesCount(a.words[i] ^ b.words[i])
+	}
+
+	return c
+}
+
+// IntersectionCount returns the number of bits set in both a and b. The
+// bit capacities of a and b must be equal.
+func (a *LMask) IntersectionCount(b *LMask) int {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	var c int
+	for i := 0; i < len(a.words); i++ {
+		c += bits.OnesCount(a.words[i] & b.words[i])
+	}
+
+	return c
+}
+
+// Intersects determines if any bit is set in both a and b. The bit
+// capacities of a and b must be equal.
+func (a *LMask) Intersects(b *LMask) bool {
+	return !a.IsDisjoint(b)
+}
+
+// IsDisjoint determines if no bit is set in both a and b. The bit
+// capacities of a and b must be equal.
+func (a *LMask) IsDisjoint(b *LMask) bool {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	for i := 0; i < len(a.words); i++ {
+		if a.words[i]&b.words[i] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsSubset determines if every bit set in a is set in b. That is, a is
+// a subset of b if b masks a. The bit capacities of a and b must be
+// equal.
+func (a *LMask) IsSubset(b *LMask) bool {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	for i := 0; i < len(a.words); i++ {
+		if a.words[i]&^b.words[i] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Jaccard returns the Jaccard index of a and b, which is the number of
+// bits set in both divided by the number of bits set in either. If no
+// bits are set in either, then it is one. The bit capacities of a and b
+// must be equal.
+func (a *LMask) Jaccard(b *LMask) float64 {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	var and, or int
+	for i := 0; i < len(a.words); i++ {
+		and += bits.OnesCount(a.words[i] & b.words[i])
+		or += bits.OnesCount(a.words[i] | b.words[i])
+	}
+
+	if or == 0 {
+		return 1
+	}
+
+	return float64(and) / float64(or)
+}
+
+// UnionCount returns the number of bits set in either a or b. The bit
+// capacities of a and b must be equal.
+func (a *LMask) UnionCount(b *LMask) int {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	var c int
+	for i := 0; i < len(a.words); i++ {
+		c += bits.OnesCount(a.words[i] | b.words[i])
+	}
+
+	return c
+}
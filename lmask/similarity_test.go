@@ -0,0 +1,66 @@
+package lmask
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, bitCap := range []int{0, 1, WordBitCap, 3*WordBitCap + 5} {
+		vals := []*LMask{Zero(bitCap), Max(bitCap), randomMask(r, bitCap), randomMask(r, bitCap)}
+		vals = append(vals, vals[2].Copy().And(vals[3]), vals[2].Copy().AndNot(vals[3]))
+		for _, a := range vals {
+			for _, b := range vals {
+				var (
+					and = a.Copy().And(b).Count()
+					or  = a.Copy().Or(b).Count()
+				)
+
+				if exp, rec := a.Copy().XOr(b).Count(), a.HammingDistance(b); exp != rec {
+					t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+				}
+
+				if rec := a.IntersectionCount(b); and != rec {
+					t.Errorf("\nexpected %d\nreceived %d\n", and, rec)
+				}
+
+				if rec := a.UnionCount(b); or != rec {
+					t.Errorf("\nexpected %d\nreceived %d\n", or, rec)
+				}
+
+				if exp, rec := 0 < and, a.Intersects(b); exp != rec || exp == a.IsDisjoint(b) {
+					t.Errorf("\nexpected intersects %t\nreceived %t\n", exp, rec)
+				}
+
+				if exp, rec := b.Masks(a), a.IsSubset(b); exp != rec {
+					t.Errorf("\nexpected subset %t\nreceived %t\n", exp, rec)
+				}
+
+				expJ, expD := 1.0, 1.0
+				if 0 < or {
+					expJ = float64(and) / float64(or)
+					expD = float64(2*and) / float64(a.Count()+b.Count())
+				}
+
+				if rec := a.Jaccard(b); expJ != rec {
+					t.Errorf("\nexpected %f\nreceived %f\n", expJ, rec)
+				}
+
+				if rec := a.Dice(b); expD != rec {
+					t.Errorf("\nexpected %f\nreceived %f\n", expD, rec)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkSimilarity(b *testing.B) {
+	r := rand.New(rand.NewSource(0))
+	x, y := randomMask(r, 1<<16), randomMask(r, 1<<16)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = x.Jaccard(y)
+	}
+}
@@ -0,0 +1,76 @@
+package lmask
+
+import (
+	"fmt"
+	"iter"
+)
+
+// Combinations returns an iterator over the bitmasks of bit capacity n
+// having exactly k bits set, in increasing order. Each is found from the
+// last as in Gosper's hack. If k is not on range [0, n], then there are
+// no combinations. Each bitmask yielded is a new bitmask.
+func Combinations(n, k int) iter.Seq[*LMask] {
+	return func(yield func(*LMask) bool) {
+		if k < 0 || n < k {
+			return
+		}
+
+		s := Zero(n).SetRange(0, k)
+		for yield(s.Copy()) {
+			// Move the lowest run of set bits up by one and return all
+			// but one of its bits to the bottom.
+			p := s.NextBit(-1)
+			q := p
+			for q < n && s.MasksBit(q) {
+				q++
+			}
+
+			if n <= q {
+				return
+			}
+
+			s.ClrRange(p, q).SetBit(q).SetRange(0, q-p-1)
+		}
+	}
+}
+
+// Submasks returns an iterator over the bitmasks having only bits set in
+// m, in decreasing order from m to zero. Each bitmask yielded is a new
+// bitmask.
+func Submasks(m *LMask) iter.Seq[*LMask] {
+	return func(yield func(*LMask) bool) {
+		s := m.Copy()
+		for yield(s.Copy()) && !s.Dec() {
+			s.And(m)
+		}
+	}
+}
+
+// Supersets returns an iterator over the bitmasks having every bit set
+// in m and any of the lowest n bits set, in increasing order from m. If
+// m has a bit set that is not one of the lowest n bits, then there are
+// no supersets. Each bitmask yielded is a new bitmask. Supersets panics
+// with an error wrapping ErrBitOutOfRange if n is not on range
+// [0, bitCap].
+func Supersets(m *LMask, n int) iter.Seq[*LMask] {
+	if n < 0 || m.bitCap < n {
+		panic(fmt.Errorf("%w: %d bits not on range [0, %d]", ErrBitOutOfRange, n, m.bitCap))
+	}
+
+	return func(yield func(*LMask) bool) {
+		if m.NextBit(n-1) < m.bitCap {
+			return
+		}
+
+		s := m.Copy()
+		for yield(s.Copy()) && !s.Inc() {
+			// Only the lowest n bits were set, so a carry beyond them
+			// sets bit n.
+			if n < s.bitCap && s.MasksBit(n) {
+				return
+			}
+
+			s.Or(m)
+		}
+	}
+}
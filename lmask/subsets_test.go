@@ -0,0 +1,97 @@
+package lmask
+
+import (
+	"errors"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/nathangreene3/bitmask/umask"
+)
+
+func TestSubsets(t *testing.T) {
+	// Agree with UMask on small bitmasks.
+	for n := 0; n <= 6; n++ {
+		for k := -1; k <= n+1; k++ {
+			exp := slices.Collect(umask.Combinations(n, k))
+			if rec := lowWords(Combinations(n, k)); !slices.Equal(exp, rec) {
+				t.Errorf("\nexpected %b\nreceived %b\n", exp, rec)
+			}
+		}
+	}
+
+	for _, m := range []umask.UMask{0, 0b101, 0b110010, 0b111111} {
+		a := FromBits(6, m.Bits()...)
+		if exp, rec := slices.Collect(umask.Submasks(m)), lowWords(Submasks(a)); !slices.Equal(exp, rec) {
+			t.Errorf("\nexpected %b\nreceived %b\n", exp, rec)
+		}
+
+		for n := 0; n <= 6; n++ {
+			if exp, rec := slices.Collect(umask.Supersets(m, n)), lowWords(Supersets(a, n)); !slices.Equal(exp, rec) {
+				t.Errorf("\nexpected %b within %d bits\nreceived %b\n", exp, n, rec)
+			}
+		}
+	}
+
+	// Combinations beyond a word are distinct and in increasing order.
+	var (
+		n, count = 2*WordBitCap + 3, 0
+		prev     *LMask
+	)
+
+	for s := range Combinations(n, 2) {
+		if s.Count() != 2 || prev != nil && prev.Cmp(s) != -1 {
+			t.Errorf("\nexpected a combination after %v\nreceived %v\n", prev, s)
+		}
+
+		prev = s
+		count++
+	}
+
+	if exp := n * (n - 1) / 2; exp != count {
+		t.Errorf("\nexpected %d combinations\nreceived %d\n", exp, count)
+	}
+
+	m := FromBits(n, 0, WordBitCap-1, WordBitCap, n-1)
+	if exp, rec := 1<<m.Count(), len(slices.Collect(Submasks(m))); exp != rec {
+		t.Errorf("\nexpected %d submasks\nreceived %d\n", exp, rec)
+	}
+
+	m = Max(n).ClrBits(0, WordBitCap, n-1)
+	if exp, rec := 1<<(n-m.Count()), len(slices.Collect(Supersets(m, n))); exp != rec {
+		t.Errorf("\nexpected %d supersets\nreceived %d\n", exp, rec)
+	}
+
+	// Supersets within the lowest bits of a wider bitmask.
+	low := m.Copy().ClrRange(WordBitCap+1, n)
+	if exp, rec := 1<<(WordBitCap+1-low.Count()), len(slices.Collect(Supersets(low, WordBitCap+1))); exp != rec {
+		t.Errorf("\nexpected %d supersets\nreceived %d\n", exp, rec)
+	}
+
+	for _, n := range []int{-1, m.BitCap() + 1} {
+		func() {
+			defer func() {
+				if err, _ := recover().(error); !errors.Is(err, ErrBitOutOfRange) {
+					t.Errorf("\nexpected %v for %d bits\nreceived %v\n", ErrBitOutOfRange, n, err)
+				}
+			}()
+
+			Supersets(m, n)
+		}()
+	}
+}
+
+// lowWords collects the lowest word of each bitmask of an iterator.
+func lowWords(seq iter.Seq[*LMask]) []umask.UMask {
+	var ws []umask.UMask
+	for a := range seq {
+		var w uint
+		if 0 < len(a.words) {
+			w = a.words[0]
+		}
+
+		ws = append(ws, umask.UMask(w))
+	}
+
+	return ws
+}
@@ -0,0 +1,438 @@
+package pmask
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+
+	"github.com/nathangreene3/bitmask/lmask"
+)
+
+const (
+	// fanoutBits is the number of bits of a bit position indexing the
+	// children of each internal node.
+	fanoutBits = 5
+
+	// fanout is the number of children of each internal node.
+	fanout = 1 << fanoutBits
+
+	// leafBitCap is the number of bits held by each leaf.
+	leafBitCap = 512
+
+	// leafWords is the number of words held by each leaf.
+	leafWords = leafBitCap / lmask.WordBitCap
+)
+
+// PMask is a persistent implementation of a bitmask having arbitrary
+// precision. A PMask is never modified. Instead, setting or clearing
+// bits returns a new version sharing every unchanged part of the old
+// one, so keeping a version as a snapshot costs nothing.
+//
+// Bits are stored in a trie of fixed height. Each leaf holds 512 bits
+// and each internal node has 32 children. A missing child has no bits
+// set, so a sparse bitmask stores only the leaves having bits set.
+type PMask struct {
+	bitCap, height int
+	root           *node
+}
+
+// node is a node of the trie. Leaves hold words and internal nodes hold
+// children. A node is never modified once built and is never empty;
+// empty subtrees are nil.
+type node struct {
+	count int
+	kids  []*node
+	words []uint
+}
+
+// --------------------------------------------------------------------
+// Constructors
+// --------------------------------------------------------------------
+
+// FromBits returns a bitmask of a given bit capacity with the specified
+// bits set.
+func FromBits(bitCap int, bits ...int) PMask {
+	return Zero(bitCap).SetBits(bits...)
+}
+
+// FromLMask returns a bitmask having the same bit capacity and bits set
+// as a given bitmask.
+func FromLMask(m *lmask.LMask) PMask {
+	a := Zero(m.BitCap())
+	a.root = build(m.Words(), a.height)
+	return a
+}
+
+// Zero returns a bitmask with no bits set.
+func Zero(bitCap int) PMask {
+	var h int
+	for n := leafBitCap; n < bitCap; n *= fanout {
+		h++
+	}
+
+	return PMask{bitCap: bitCap, height: h}
+}
+
+// --------------------------------------------------------------------
+// Set functionality
+// --------------------------------------------------------------------
+
+// All returns an iterator over the set bits in increasing order.
+func (a PMask) All() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		a.root.all(a.height, 0, yield)
+	}
+}
+
+// BitCap returns the bit capacity.
+func (a PMask) BitCap() int {
+	return a.bitCap
+}
+
+// Bits returns the bits that are set.
+func (a PMask) Bits() []int {
+	bits := make([]int, 0, a.Count())
+	for bit := range a.All() {
+		bits = append(bits, bit)
+	}
+
+	return bits
+}
+
+// ClrBit returns a version of a with a bit unset. If the bit is not on
+// range [0, bitCap), then ClrBit panics with an error wrapping
+// lmask.ErrBitOutOfRange.
+func (a PMask) ClrBit(bit int) PMask {
+	if bit < 0 || a.bitCap <= bit {
+		panic(bitOutOfRange(a, bit))
+	}
+
+	a.root = a.root.with(a.height, bit, false)
+	return a
+}
+
+// ClrBits returns a version of a with several bits unset. If any bit is
+// not on range [0, bitCap), then ClrBits panics with an error wrapping
+// lmask.ErrBitOutOfRange.
+func (a PMask) ClrBits(bits ...int) PMask {
+	for i := 0; i < len(bits); i++ {
+		a = a.ClrBit(bits[i])
+	}
+
+	return a
+}
+
+// Count returns the number of bits set.
+func (a PMask) Count() int {
+	return a.root.size()
+}
+
+// Diff returns an iterator over the bits set in exactly one of a and b,
+// in increasing order. Only the parts of the trie not shared by a and b
+// are visited, so comparing a version with a recent snapshot costs time
+// proportional to the number of bits changed since. The bit capacities
+// of a and b must be equal.
+func (a PMask) Diff(b PMask) iter.Seq[int] {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	return func(yield func(int) bool) {
+		diff(a.root, b.root, a.height, 0, yield)
+	}
+}
+
+// Equals determines if two bitmasks have the same bit capacity and the
+// same bits set. Shared parts of the trie are not compared.
+func (a PMask) Equals(b PMask) bool {
+	return a.bitCap == b.bitCap && equal(a.root, b.root, a.height)
+}
+
+// LMask returns a bitmask having the same bit capacity and bits set.
+func (a PMask) LMask() *lmask.LMask {
+	words := make([]uint, (a.bitCap+lmask.WordBitCap-1)/lmask.WordBitCap)
+	a.root.fill(a.height, words)
+	return lmask.FromWords(words...).SetBitCap(a.bitCap)
+}
+
+// MasksBit determines if a bit is set. Bits not on range [0, bitCap)
+// are never set.
+func (a PMask) MasksBit(bit int) bool {
+	if bit < 0 || a.bitCap <= bit {
+		return false
+	}
+
+	n := a.root
+	for h := a.height; 0 < h && n != nil; h-- {
+		span := spanOf(h - 1)
+		n, bit = n.kids[bit/span], bit%span
+	}
+
+	if n == nil {
+		return false
+	}
+
+	k := bit / lmask.WordBitCap
+	return n.words[k]&(1<<(bit-k*lmask.WordBitCap)) != 0
+}
+
+// SetBit returns a version of a with a bit set. If the bit is not on
+// range [0, bitCap), then SetBit panics with an error wrapping
+// lmask.ErrBitOutOfRange.
+func (a PMask) SetBit(bit int) PMask {
+	if bit < 0 || a.bitCap <= bit {
+		panic(bitOutOfRange(a, bit))
+	}
+
+	a.root = a.root.with(a.height, bit, true)
+	return a
+}
+
+// SetBits returns a version of a with several bits set. If any bit is
+// not on range [0, bitCap), then SetBits panics with an error wrapping
+// lmask.ErrBitOutOfRange.
+func (a PMask) SetBits(bits ...int) PMask {
+	for i := 0; i < len(bits); i++ {
+		a = a.SetBit(bits[i])
+	}
+
+	return a
+}
+
+// String returns the base-10 integer representation of a bitmask.
+func (a PMask) String() string {
+	return a.LMask().String()
+}
+
+// --------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------
+
+// all yields the bits set in a subtree of a given height whose lowest
+// bit is at an offset. It returns false if yield does.
+func (n *node) all(h, offset int, yield func(int) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	if h == 0 {
+		return yieldWords(n.words, nil, offset, yield)
+	}
+
+	span := spanOf(h - 1)
+	for i := 0; i < fanout; i++ {
+		if !n.kids[i].all(h-1, offset+i*span, yield) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fill copies the words of a subtree of a given height into a list of
+// words beginning at the subtree's lowest bit. Words beyond the end of
+// the list are ignored.
+func (n *node) fill(h int, words []uint) {
+	if n == nil {
+		return
+	}
+
+	if h == 0 {
+		copy(words, n.words)
+		return
+	}
+
+	spanWords := spanOf(h-1) / lmask.WordBitCap
+	for i := 0; i < fanout && i*spanWords < len(words); i++ {
+		n.kids[i].fill(h-1, words[i*spanWords:])
+	}
+}
+
+// size returns the number of bits set in a subtree.
+func (n *node) size() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.count
+}
+
+// with returns a subtree of a given height having a bit set or unset,
+// copying only the nodes on the path to the bit. If the bit is already
+// as requested, then the subtree itself is returned.
+func (n *node) with(h, bit int, set bool) *node {
+	if h == 0 {
+		var (
+			k = bit / lmask.WordBitCap
+			c = uint(1) << (bit - k*lmask.WordBitCap)
+		)
+
+		if (n != nil && n.words[k]&c != 0) == set {
+			return n
+		}
+
+		m := &node{count: n.size(), words: make([]uint, leafWords)}
+		if n != nil {
+			copy(m.words, n.words)
+		}
+
+		m.words[k] ^= c
+		if set {
+			m.count++
+		} else if m.count--; m.count == 0 {
+			return nil
+		}
+
+		return m
+	}
+
+	var (
+		span = spanOf(h - 1)
+		i    = bit / span
+		kid  *node
+	)
+
+	if n != nil {
+		kid = n.kids[i]
+	}
+
+	newKid := kid.with(h-1, bit-i*span, set)
+	if newKid == kid {
+		return n
+	}
+
+	m := &node{count: n.size() - kid.size() + newKid.size(), kids: make([]*node, fanout)}
+	if m.count == 0 {
+		return nil
+	}
+
+	if n != nil {
+		copy(m.kids, n.kids)
+	}
+
+	m.kids[i] = newKid
+	return m
+}
+
+// bitOutOfRange returns an error indicating a bit is not on range
+// [0, bitCap).
+func bitOutOfRange(a PMask, bit int) error {
+	return fmt.Errorf("%w: %d not on range [0, %d)", lmask.ErrBitOutOfRange, bit, a.bitCap)
+}
+
+// build returns a subtree of a given height holding a list of words
+// beginning at the subtree's lowest bit.
+func build(words []uint, h int) *node {
+	if h == 0 {
+		n := &node{words: make([]uint, leafWords)}
+		copy(n.words, words)
+		for i := 0; i < len(n.words); i++ {
+			n.count += bits.OnesCount(n.words[i])
+		}
+
+		if n.count == 0 {
+			return nil
+		}
+
+		return n
+	}
+
+	var (
+		n         = &node{kids: make([]*node, fanout)}
+		spanWords = spanOf(h-1) / lmask.WordBitCap
+	)
+
+	for i := 0; i < fanout && i*spanWords < len(words); i++ {
+		n.kids[i] = build(words[i*spanWords:min((i+1)*spanWords, len(words))], h-1)
+		n.count += n.kids[i].size()
+	}
+
+	if n.count == 0 {
+		return nil
+	}
+
+	return n
+}
+
+// diff yields the bits set in exactly one of two subtrees of a given
+// height whose lowest bit is at an offset, skipping shared subtrees. It
+// returns false if yield does.
+func diff(x, y *node, h, offset int, yield func(int) bool) bool {
+	switch {
+	case x == y:
+		return true
+	case x == nil:
+		return y.all(h, offset, yield)
+	case y == nil:
+		return x.all(h, offset, yield)
+	case h == 0:
+		return yieldWords(x.words, y.words, offset, yield)
+	}
+
+	span := spanOf(h - 1)
+	for i := 0; i < fanout; i++ {
+		if !diff(x.kids[i], y.kids[i], h-1, offset+i*span, yield) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equal determines if two subtrees of a given height have the same bits
+// set, skipping shared subtrees.
+func equal(x, y *node, h int) bool {
+	switch {
+	case x == y:
+		return true
+	case x.size() != y.size():
+		return false
+	case h == 0:
+		for i := 0; i < leafWords; i++ {
+			if x.words[i] != y.words[i] {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for i := 0; i < fanout; i++ {
+		if !equal(x.kids[i], y.kids[i], h-1) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// spanOf returns the number of bits held by a subtree of a given height.
+func spanOf(h int) int {
+	return leafBitCap << (fanoutBits * h)
+}
+
+// uneqBitCaps returns an error indicating two bitmasks do not have the
+// same bit capacity.
+func uneqBitCaps(a, b PMask) error {
+	return fmt.Errorf("%w: %d and %d", lmask.ErrUnequalBitCaps, a.bitCap, b.bitCap)
+}
+
+// yieldWords yields the bits set in exactly one of two lists of words
+// beginning at an offset. A nil list has no bits set. It returns false
+// if yield does.
+func yieldWords(x, y []uint, offset int, yield func(int) bool) bool {
+	for k := 0; k < len(x); k++ {
+		w := x[k]
+		if y != nil {
+			w ^= y[k]
+		}
+
+		for ; w != 0; w &= w - 1 {
+			if !yield(offset + k*lmask.WordBitCap + bits.TrailingZeros(w)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,190 @@
+package pmask
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/nathangreene3/bitmask/lmask"
+)
+
+func TestFromToLMask(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, bitCap := range []int{0, 1, 10, lmask.WordBitCap, leafBitCap - 1, leafBitCap, leafBitCap + 1, 3*spanOf(1) + 100} {
+		masks := []*lmask.LMask{lmask.Zero(bitCap), lmask.Zero(bitCap).Not(), lmask.Zero(bitCap)}
+		for i := 0; i < bitCap/10; i++ {
+			masks[2].SetBit(r.Intn(bitCap))
+		}
+
+		for _, exp := range masks {
+			a := FromLMask(exp)
+			if rec := a.LMask(); !exp.Equals(rec) {
+				t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+			}
+
+			if exp, rec := exp.Count(), a.Count(); exp != rec {
+				t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+			}
+
+			if exp, rec := exp.Bits(), a.Bits(); !slices.Equal(exp, rec) {
+				t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+			}
+
+			if !a.Equals(FromBits(bitCap, a.Bits()...)) {
+				t.Errorf("\nexpected %v to equal itself\n", a)
+			}
+		}
+	}
+}
+
+func TestSetClrBit(t *testing.T) {
+	var (
+		r        = rand.New(rand.NewSource(0))
+		bitCap   = 2*spanOf(1) + 7
+		exp      = lmask.Zero(bitCap)
+		a        = Zero(bitCap)
+		versions []PMask
+		expected []*lmask.LMask
+	)
+
+	for i := 0; i < 5000; i++ {
+		bit := r.Intn(bitCap)
+		if r.Intn(3) == 0 {
+			exp.ClrBit(bit)
+			a = a.ClrBit(bit)
+		} else {
+			exp.SetBit(bit)
+			a = a.SetBit(bit)
+		}
+
+		if exp, rec := exp.MasksBit(bit), a.MasksBit(bit); exp != rec {
+			t.Fatalf("\nexpected %t\nreceived %t\n", exp, rec)
+		}
+
+		if i%500 == 0 {
+			versions, expected = append(versions, a), append(expected, exp.Copy())
+		}
+	}
+
+	// Older versions are never modified.
+	for i := 0; i < len(versions); i++ {
+		if rec := versions[i].LMask(); !expected[i].Equals(rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", expected[i], rec)
+		}
+
+		if exp, rec := expected[i].Count(), versions[i].Count(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+	}
+
+	// Clearing every bit leaves no nodes.
+	if rec := a.ClrBits(a.Bits()...); rec.root != nil || !rec.Equals(Zero(bitCap)) {
+		t.Errorf("\nexpected an empty trie\nreceived %v\n", rec)
+	}
+
+	for _, bit := range []int{-1, bitCap} {
+		if a.MasksBit(bit) {
+			t.Errorf("\nexpected bit %d to be unset\n", bit)
+		}
+
+		func() {
+			defer func() {
+				if err, ok := recover().(error); !ok || !errors.Is(err, lmask.ErrBitOutOfRange) {
+					t.Errorf("\nexpected %v\nreceived %v\n", lmask.ErrBitOutOfRange, err)
+				}
+			}()
+
+			a.SetBit(bit)
+		}()
+	}
+}
+
+func TestSharing(t *testing.T) {
+	var (
+		bitCap = 4 * spanOf(1)
+		a      = FromBits(bitCap, 0, spanOf(1), 3*spanOf(1)+1)
+		b      = a.SetBit(spanOf(1) + 1)
+	)
+
+	// Only the path to the changed leaf is copied.
+	if a.root == b.root || a.root.kids[1] == b.root.kids[1] {
+		t.Errorf("\nexpected the path to bit %d to be copied\n", spanOf(1)+1)
+	}
+
+	for _, i := range []int{0, 3} {
+		if a.root.kids[i] != b.root.kids[i] {
+			t.Errorf("\nexpected child %d to be shared\n", i)
+		}
+	}
+
+	// Setting a set bit or clearing an unset bit changes nothing.
+	if b.SetBit(0).root != b.root || b.ClrBit(2).root != b.root {
+		t.Errorf("\nexpected no copying\n")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	var (
+		r      = rand.New(rand.NewSource(0))
+		bitCap = 5*spanOf(1) + 3
+		a      = Zero(bitCap)
+	)
+
+	for i := 0; i < 2000; i++ {
+		a = a.SetBit(r.Intn(bitCap))
+	}
+
+	b := a
+	for i := 0; i < 50; i++ {
+		bit := r.Intn(bitCap)
+		if a.MasksBit(bit) {
+			b = b.ClrBit(bit)
+		} else {
+			b = b.SetBit(bit)
+		}
+	}
+
+	exp := a.LMask().XOr(b.LMask()).Bits()
+	if rec := slices.Collect(a.Diff(b)); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+	}
+
+	if rec := slices.Collect(b.Diff(a)); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+	}
+
+	if a.Equals(b) {
+		t.Errorf("\nexpected %v and %v to differ\n", a, b)
+	}
+
+	// Bitmasks built separately compare equal.
+	c := FromLMask(b.LMask())
+	if !c.Equals(b) {
+		t.Errorf("\nexpected %v\nreceived %v\n", b, c)
+	}
+
+	if rec := slices.Collect(c.Diff(b)); len(rec) != 0 {
+		t.Errorf("\nexpected no differences\nreceived %v\n", rec)
+	}
+
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, lmask.ErrUnequalBitCaps) {
+			t.Errorf("\nexpected %v\nreceived %v\n", lmask.ErrUnequalBitCaps, err)
+		}
+	}()
+
+	a.Diff(Zero(bitCap + 1))
+}
+
+func BenchmarkSetBit(b *testing.B) {
+	var (
+		bitCap = 1 << 20
+		a      = FromLMask(lmask.Max(bitCap))
+	)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a = a.ClrBit(i % bitCap)
+	}
+}
@@ -0,0 +1,603 @@
+package rmask
+
+import (
+	"math/bits"
+	"sort"
+)
+
+const (
+	// chunkBits is the number of low-order bits of a bit stored within a
+	// container. The remaining high-order bits form the container's key.
+	chunkBits = 16
+
+	// chunkSize is the number of bits a container can hold.
+	chunkSize = 1 << chunkBits
+
+	// chunkMax is the largest bit a container can hold.
+	chunkMax = chunkSize - 1
+
+	// arrayMax is the maximum number of bits an array container holds
+	// before it is converted to a bitmap container.
+	arrayMax = 4096
+
+	// bitmapWords is the number of words in a bitmap container.
+	bitmapWords = chunkSize / 64
+
+	// runMax is the maximum number of runs a run container holds before
+	// it is no smaller than a bitmap container and is converted.
+	runMax = 8 * bitmapWords / 4
+)
+
+// container is a set of bits on range [0, chunkSize). Mutating methods
+// return the container that should replace the receiver as it may
+// change representation.
+type container interface {
+	// add sets a bit.
+	add(x uint16) container
+
+	// remove unsets a bit.
+	remove(x uint16) container
+
+	// contains determines if a bit is set.
+	contains(x uint16) bool
+
+	// count returns the number of bits set.
+	count() int
+
+	// next returns the smallest set bit greater than or equal to x. If
+	// there is no such bit, then -1 is returned.
+	next(x int) int
+
+	// prev returns the largest set bit less than or equal to x. If there
+	// is no such bit, then -1 is returned.
+	prev(x int) int
+
+	// clone returns a deep copy.
+	clone() container
+
+	// size returns the approximate number of bytes used.
+	size() int
+
+	// toBitmap returns a new bitmap container holding the same bits.
+	toBitmap() *bitmapContainer
+}
+
+// --------------------------------------------------------------------
+// Array container
+// --------------------------------------------------------------------
+
+// arrayContainer is a sorted list of set bits.
+type arrayContainer []uint16
+
+func (c arrayContainer) add(x uint16) container {
+	n := len(c)
+	if n == 0 || c[n-1] < x {
+		if n < arrayMax {
+			return append(c, x)
+		}
+
+		return c.toBitmap().add(x)
+	}
+
+	i := c.search(x)
+	if c[i] == x {
+		return c
+	}
+
+	if arrayMax <= n {
+		return c.toBitmap().add(x)
+	}
+
+	c = append(c, 0)
+	copy(c[i+1:], c[i:])
+	c[i] = x
+	return c
+}
+
+func (c arrayContainer) remove(x uint16) container {
+	i := c.search(x)
+	if i == len(c) || c[i] != x {
+		return c
+	}
+
+	copy(c[i:], c[i+1:])
+	return c[:len(c)-1]
+}
+
+func (c arrayContainer) contains(x uint16) bool {
+	i := c.search(x)
+	return i < len(c) && c[i] == x
+}
+
+func (c arrayContainer) count() int {
+	return len(c)
+}
+
+func (c arrayContainer) next(x int) int {
+	if chunkMax < x {
+		return -1
+	}
+
+	if i := sort.Search(len(c), func(i int) bool { return x <= int(c[i]) }); i < len(c) {
+		return int(c[i])
+	}
+
+	return -1
+}
+
+func (c arrayContainer) prev(x int) int {
+	if i := sort.Search(len(c), func(i int) bool { return x < int(c[i]) }) - 1; 0 <= i {
+		return int(c[i])
+	}
+
+	return -1
+}
+
+func (c arrayContainer) clone() container {
+	return append(make(arrayContainer, 0, len(c)), c...)
+}
+
+func (c arrayContainer) size() int {
+	return 2 * len(c)
+}
+
+func (c arrayContainer) toBitmap() *bitmapContainer {
+	b := &bitmapContainer{n: len(c)}
+	for _, x := range c {
+		b.words[x>>6] |= 1 << (x & 63)
+	}
+
+	return b
+}
+
+// filter keeps only the bits in c that are (or are not) in d. The
+// result reuses the memory of c.
+func (c arrayContainer) filter(d container, keep bool) arrayContainer {
+	r := c[:0]
+	for _, x := range c {
+		if d.contains(x) == keep {
+			r = append(r, x)
+		}
+	}
+
+	return r
+}
+
+// search returns the index of the smallest value greater than or equal
+// to x.
+func (c arrayContainer) search(x uint16) int {
+	return sort.Search(len(c), func(i int) bool { return x <= c[i] })
+}
+
+// --------------------------------------------------------------------
+// Bitmap container
+// --------------------------------------------------------------------
+
+// bitmapContainer is a dense set of bits.
+type bitmapContainer struct {
+	n     int
+	words [bitmapWords]uint64
+}
+
+func (c *bitmapContainer) add(x uint16) container {
+	w, b := x>>6, uint64(1)<<(x&63)
+	if c.words[w]&b == 0 {
+		c.words[w] |= b
+		c.n++
+	}
+
+	return c
+}
+
+func (c *bitmapContainer) remove(x uint16) container {
+	w, b := x>>6, uint64(1)<<(x&63)
+	if c.words[w]&b != 0 {
+		c.words[w] &^= b
+		c.n--
+	}
+
+	return c.normalize()
+}
+
+func (c *bitmapContainer) contains(x uint16) bool {
+	return c.words[x>>6]&(1<<(x&63)) != 0
+}
+
+func (c *bitmapContainer) count() int {
+	return c.n
+}
+
+func (c *bitmapContainer) next(x int) int {
+	if chunkMax < x {
+		return -1
+	}
+
+	w := x >> 6
+	if word := c.words[w] >> (x & 63) << (x & 63); word != 0 {
+		return w<<6 + bits.TrailingZeros64(word)
+	}
+
+	for w++; w < bitmapWords; w++ {
+		if c.words[w] != 0 {
+			return w<<6 + bits.TrailingZeros64(c.words[w])
+		}
+	}
+
+	return -1
+}
+
+func (c *bitmapContainer) prev(x int) int {
+	if x < 0 {
+		return -1
+	}
+
+	w, s := x>>6, 63-x&63
+	if word := c.words[w] << s >> s; word != 0 {
+		return w<<6 + 63 - bits.LeadingZeros64(word)
+	}
+
+	for w--; 0 <= w; w-- {
+		if c.words[w] != 0 {
+			return w<<6 + 63 - bits.LeadingZeros64(c.words[w])
+		}
+	}
+
+	return -1
+}
+
+func (c *bitmapContainer) clone() container {
+	d := *c
+	return &d
+}
+
+func (c *bitmapContainer) size() int {
+	return 8 * bitmapWords
+}
+
+func (c *bitmapContainer) toBitmap() *bitmapContainer {
+	d := *c
+	return &d
+}
+
+// normalize returns an array container if the number of bits set is
+// small enough. Otherwise, c is returned.
+func (c *bitmapContainer) normalize() container {
+	if arrayMax < c.n {
+		return c
+	}
+
+	a := make(arrayContainer, 0, c.n)
+	for w := 0; w < bitmapWords; w++ {
+		for word := c.words[w]; word != 0; word &= word - 1 {
+			a = append(a, uint16(w<<6+bits.TrailingZeros64(word)))
+		}
+	}
+
+	return a
+}
+
+// recount updates the number of bits set.
+func (c *bitmapContainer) recount() *bitmapContainer {
+	c.n = 0
+	for w := 0; w < bitmapWords; w++ {
+		c.n += bits.OnesCount64(c.words[w])
+	}
+
+	return c
+}
+
+// --------------------------------------------------------------------
+// Run container
+// --------------------------------------------------------------------
+
+// run is an inclusive range of set bits.
+type run struct {
+	start, last uint16
+}
+
+// runContainer is a sorted list of non-overlapping, non-adjacent runs.
+type runContainer []run
+
+func (c runContainer) add(x uint16) container {
+	i := c.search(x)
+	if i < len(c) && c[i].start <= x {
+		return c
+	}
+
+	var (
+		joinPrev = 0 < i && int(c[i-1].last)+1 == int(x)
+		joinNext = i < len(c) && int(x)+1 == int(c[i].start)
+	)
+
+	switch {
+	case joinPrev && joinNext:
+		c[i-1].last = c[i].last
+		return append(c[:i], c[i+1:]...)
+	case joinPrev:
+		c[i-1].last = x
+	case joinNext:
+		c[i].start = x
+	default:
+		c = append(c, run{})
+		copy(c[i+1:], c[i:])
+		c[i] = run{start: x, last: x}
+	}
+
+	return c.normalize()
+}
+
+func (c runContainer) remove(x uint16) container {
+	i := c.search(x)
+	if i == len(c) || x < c[i].start {
+		return c
+	}
+
+	switch r := c[i]; {
+	case r.start == r.last:
+		return append(c[:i], c[i+1:]...)
+	case x == r.start:
+		c[i].start++
+	case x == r.last:
+		c[i].last--
+	default:
+		c = append(c, run{})
+		copy(c[i+2:], c[i+1:])
+		c[i].last = x - 1
+		c[i+1] = run{start: x + 1, last: r.last}
+	}
+
+	return c.normalize()
+}
+
+func (c runContainer) contains(x uint16) bool {
+	i := c.search(x)
+	return i < len(c) && c[i].start <= x
+}
+
+func (c runContainer) count() int {
+	var n int
+	for _, r := range c {
+		n += int(r.last-r.start) + 1
+	}
+
+	return n
+}
+
+func (c runContainer) next(x int) int {
+	if chunkMax < x {
+		return -1
+	}
+
+	if i := c.search(uint16(x)); i < len(c) {
+		return max(int(c[i].start), x)
+	}
+
+	return -1
+}
+
+func (c runContainer) prev(x int) int {
+	if i := sort.Search(len(c), func(i int) bool { return x < int(c[i].start) }) - 1; 0 <= i {
+		return min(int(c[i].last), x)
+	}
+
+	return -1
+}
+
+func (c runContainer) clone() container {
+	return append(make(runContainer, 0, len(c)), c...)
+}
+
+func (c runContainer) size() int {
+	return 4 * len(c)
+}
+
+func (c runContainer) toBitmap() *bitmapContainer {
+	b := &bitmapContainer{}
+	for _, r := range c {
+		for x := int(r.start); x <= int(r.last); x++ {
+			b.words[x>>6] |= 1 << (x & 63)
+		}
+	}
+
+	return b.recount()
+}
+
+// normalize returns a bitmap or array container if there are too many
+// runs to be smaller than a bitmap container. Otherwise, c is returned.
+func (c runContainer) normalize() container {
+	if len(c) <= runMax {
+		return c
+	}
+
+	return c.toBitmap().normalize()
+}
+
+// search returns the index of the first run ending at or after x.
+func (c runContainer) search(x uint16) int {
+	return sort.Search(len(c), func(i int) bool { return x <= c[i].last })
+}
+
+// --------------------------------------------------------------------
+// Container operations
+// --------------------------------------------------------------------
+
+// and returns the bits set in both c and d. The memory of c may be
+// reused, but d is never modified.
+func and(c, d container) container {
+	if c, ok := c.(arrayContainer); ok {
+		return c.filter(d, true)
+	}
+
+	if d, ok := d.(arrayContainer); ok {
+		return d.clone().(arrayContainer).filter(c, true)
+	}
+
+	b := asBitmap(c)
+	e := asBitmap(d)
+	for w := 0; w < bitmapWords; w++ {
+		b.words[w] &= e.words[w]
+	}
+
+	return b.recount().normalize()
+}
+
+// andNot returns the bits set in c that are not set in d. The memory
+// of c may be reused, but d is never modified.
+func andNot(c, d container) container {
+	if c, ok := c.(arrayContainer); ok {
+		return c.filter(d, false)
+	}
+
+	b := asBitmap(c)
+	if e, ok := d.(arrayContainer); ok {
+		for _, x := range e {
+			b.words[x>>6] &^= 1 << (x & 63)
+		}
+	} else {
+		e := asBitmap(d)
+		for w := 0; w < bitmapWords; w++ {
+			b.words[w] &^= e.words[w]
+		}
+	}
+
+	return b.recount().normalize()
+}
+
+// or returns the bits set in either c or d. The memory of c may be
+// reused, but d is never modified.
+func or(c, d container) container {
+	if c, ok := c.(arrayContainer); ok {
+		if d, ok := d.(arrayContainer); ok && len(c)+len(d) <= arrayMax {
+			return merge(c, d, true)
+		}
+	}
+
+	b := asBitmap(c)
+	if e, ok := d.(arrayContainer); ok {
+		for _, x := range e {
+			b.words[x>>6] |= 1 << (x & 63)
+		}
+	} else {
+		e := asBitmap(d)
+		for w := 0; w < bitmapWords; w++ {
+			b.words[w] |= e.words[w]
+		}
+	}
+
+	return b.recount().normalize()
+}
+
+// xor returns the bits set in exactly one of c and d. The memory of c
+// may be reused, but d is never modified.
+func xor(c, d container) container {
+	if c, ok := c.(arrayContainer); ok {
+		if d, ok := d.(arrayContainer); ok && len(c)+len(d) <= arrayMax {
+			return merge(c, d, false)
+		}
+	}
+
+	b := asBitmap(c)
+	if e, ok := d.(arrayContainer); ok {
+		for _, x := range e {
+			b.words[x>>6] ^= 1 << (x & 63)
+		}
+	} else {
+		e := asBitmap(d)
+		for w := 0; w < bitmapWords; w++ {
+			b.words[w] ^= e.words[w]
+		}
+	}
+
+	return b.recount().normalize()
+}
+
+// merge returns a new array container holding the union of c and d. If
+// union is false, bits set in both are dropped.
+func merge(c, d arrayContainer, union bool) arrayContainer {
+	r := make(arrayContainer, 0, len(c)+len(d))
+	i, j := 0, 0
+	for i < len(c) && j < len(d) {
+		switch {
+		case c[i] < d[j]:
+			r = append(r, c[i])
+			i++
+		case d[j] < c[i]:
+			r = append(r, d[j])
+			j++
+		default:
+			if union {
+				r = append(r, c[i])
+			}
+
+			i++
+			j++
+		}
+	}
+
+	r = append(r, c[i:]...)
+	return append(r, d[j:]...)
+}
+
+// equal determines if two containers have the same bits set.
+func equal(c, d container) bool {
+	if c.count() != d.count() {
+		return false
+	}
+
+	for x := c.next(0); 0 <= x; x = c.next(x + 1) {
+		if !d.contains(uint16(x)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// optimize returns the smallest representation of a container.
+func optimize(c container) container {
+	var (
+		r = toRuns(c)
+		n = c.count()
+	)
+
+	switch rs, as := r.size(), 2*n; {
+	case rs < as && rs < 8*bitmapWords:
+		return r
+	case arrayMax < n:
+		if b, ok := c.(*bitmapContainer); ok {
+			return b
+		}
+
+		return c.toBitmap()
+	default:
+		return c.toBitmap().normalize()
+	}
+}
+
+// asBitmap returns a bitmap container holding the bits of c. If c is a
+// bitmap container, it is returned rather than copied.
+func asBitmap(c container) *bitmapContainer {
+	if b, ok := c.(*bitmapContainer); ok {
+		return b
+	}
+
+	return c.toBitmap()
+}
+
+// toRuns returns the runs of set bits in a container.
+func toRuns(c container) runContainer {
+	if r, ok := c.(runContainer); ok {
+		return r
+	}
+
+	var r runContainer
+	for x := c.next(0); 0 <= x; x = c.next(x + 1) {
+		if n := len(r); 0 < n && int(r[n-1].last)+1 == x {
+			r[n-1].last = uint16(x)
+		} else {
+			r = append(r, run{start: uint16(x), last: uint16(x)})
+		}
+	}
+
+	return r
+}
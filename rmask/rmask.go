@@ -0,0 +1,458 @@
+package rmask
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nathangreene3/bitmask/lmask"
+)
+
+// RMask is a compressed implementation of a bitmask having arbitrary
+// precision. Bits are partitioned into chunks of 2^16 bits by their
+// high-order bits and each non-empty chunk is stored in the smallest of
+// an array, bitmap, or run container, as in Roaring bitmaps.
+type RMask struct {
+	bitCap int
+	keys   []int
+	conts  []container
+}
+
+// --------------------------------------------------------------------
+// Constructors
+// --------------------------------------------------------------------
+
+// FromBits returns a bitmask of a given bit capacity with the
+// specified bits set.
+func FromBits(bitCap int, bits ...int) *RMask {
+	return Zero(bitCap).SetBits(bits...)
+}
+
+// FromLMask returns a bitmask having the same bit capacity and bits set
+// as a given bitmask.
+func FromLMask(m *lmask.LMask) *RMask {
+	bitCap := m.BitCap()
+	a := Zero(bitCap)
+	for bit := m.NextBit(-1); bit < bitCap; bit = m.NextBit(bit) {
+		a.SetBit(bit)
+	}
+
+	return a
+}
+
+// Zero returns a bitmask with no bits set.
+func Zero(bitCap int) *RMask {
+	return &RMask{bitCap: bitCap}
+}
+
+// --------------------------------------------------------------------
+// Logic functionality
+// --------------------------------------------------------------------
+
+// And sets each bit in a if the bit in b is also set. Otherwise, the
+// bit in a is unset.
+func (a *RMask) And(b *RMask) *RMask {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	var (
+		keys  = a.keys[:0]
+		conts = a.conts[:0]
+	)
+
+	for i, j := 0, 0; i < len(a.keys) && j < len(b.keys); {
+		switch {
+		case a.keys[i] < b.keys[j]:
+			i++
+		case b.keys[j] < a.keys[i]:
+			j++
+		default:
+			if c := and(a.conts[i], b.conts[j]); 0 < c.count() {
+				keys = append(keys, a.keys[i])
+				conts = append(conts, c)
+			}
+
+			i++
+			j++
+		}
+	}
+
+	for i := len(conts); i < len(a.conts); i++ {
+		a.conts[i] = nil
+	}
+
+	a.keys, a.conts = keys, conts
+	return a
+}
+
+// AndNot sets each bit in a if the bit in a is set and the bit in b
+// is not set. Otherwise, the bit in a is unset.
+func (a *RMask) AndNot(b *RMask) *RMask {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	var (
+		keys  = a.keys[:0]
+		conts = a.conts[:0]
+	)
+
+	for i, j := 0, 0; i < len(a.keys); {
+		switch {
+		case j == len(b.keys) || a.keys[i] < b.keys[j]:
+			keys = append(keys, a.keys[i])
+			conts = append(conts, a.conts[i])
+			i++
+		case b.keys[j] < a.keys[i]:
+			j++
+		default:
+			if c := andNot(a.conts[i], b.conts[j]); 0 < c.count() {
+				keys = append(keys, a.keys[i])
+				conts = append(conts, c)
+			}
+
+			i++
+			j++
+		}
+	}
+
+	for i := len(conts); i < len(a.conts); i++ {
+		a.conts[i] = nil
+	}
+
+	a.keys, a.conts = keys, conts
+	return a
+}
+
+// Or sets each bit in a if either bit in a or b is set. Otherwise,
+// the bit in a is unset.
+func (a *RMask) Or(b *RMask) *RMask {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	var (
+		keys  = make([]int, 0, len(a.keys)+len(b.keys))
+		conts = make([]container, 0, len(a.keys)+len(b.keys))
+	)
+
+	i, j := 0, 0
+	for i < len(a.keys) && j < len(b.keys) {
+		switch {
+		case a.keys[i] < b.keys[j]:
+			keys = append(keys, a.keys[i])
+			conts = append(conts, a.conts[i])
+			i++
+		case b.keys[j] < a.keys[i]:
+			keys = append(keys, b.keys[j])
+			conts = append(conts, b.conts[j].clone())
+			j++
+		default:
+			keys = append(keys, a.keys[i])
+			conts = append(conts, or(a.conts[i], b.conts[j]))
+			i++
+			j++
+		}
+	}
+
+	keys = append(keys, a.keys[i:]...)
+	conts = append(conts, a.conts[i:]...)
+	for ; j < len(b.keys); j++ {
+		keys = append(keys, b.keys[j])
+		conts = append(conts, b.conts[j].clone())
+	}
+
+	a.keys, a.conts = keys, conts
+	return a
+}
+
+// XOr sets each bit in a if exactly one bit in a and b is set.
+// Otherwise, the bit in a is unset.
+func (a *RMask) XOr(b *RMask) *RMask {
+	if a.bitCap != b.bitCap {
+		panic(uneqBitCaps(a, b))
+	}
+
+	var (
+		keys  = make([]int, 0, len(a.keys)+len(b.keys))
+		conts = make([]container, 0, len(a.keys)+len(b.keys))
+	)
+
+	i, j := 0, 0
+	for i < len(a.keys) && j < len(b.keys) {
+		switch {
+		case a.keys[i] < b.keys[j]:
+			keys = append(keys, a.keys[i])
+			conts = append(conts, a.conts[i])
+			i++
+		case b.keys[j] < a.keys[i]:
+			keys = append(keys, b.keys[j])
+			conts = append(conts, b.conts[j].clone())
+			j++
+		default:
+			if c := xor(a.conts[i], b.conts[j]); 0 < c.count() {
+				keys = append(keys, a.keys[i])
+				conts = append(conts, c)
+			}
+
+			i++
+			j++
+		}
+	}
+
+	keys = append(keys, a.keys[i:]...)
+	conts = append(conts, a.conts[i:]...)
+	for ; j < len(b.keys); j++ {
+		keys = append(keys, b.keys[j])
+		conts = append(conts, b.conts[j].clone())
+	}
+
+	a.keys, a.conts = keys, conts
+	return a
+}
+
+// --------------------------------------------------------------------
+// Additional functionality
+// --------------------------------------------------------------------
+
+// BitCap returns the bit capacity.
+func (a *RMask) BitCap() int {
+	return a.bitCap
+}
+
+// Bits returns the bits that are set in a bitmask.
+func (a *RMask) Bits() []int {
+	bits := make([]int, 0, a.Count())
+	for i, c := range a.conts {
+		k := a.keys[i] << chunkBits
+		for x := c.next(0); 0 <= x; x = c.next(x + 1) {
+			bits = append(bits, k|x)
+		}
+	}
+
+	return bits
+}
+
+// ClrBit unsets a bit. If the bit is not on range [0, bitCap), then
+// ClrBit panics with an error wrapping lmask.ErrBitOutOfRange.
+func (a *RMask) ClrBit(bit int) *RMask {
+	if bit < 0 || a.bitCap <= bit {
+		panic(bitOutOfRange(a, bit))
+	}
+
+	k := bit >> chunkBits
+	if i := a.search(k); i < len(a.keys) && a.keys[i] == k {
+		if a.conts[i] = a.conts[i].remove(uint16(bit)); a.conts[i].count() == 0 {
+			a.keys = append(a.keys[:i], a.keys[i+1:]...)
+			a.conts = append(a.conts[:i], a.conts[i+1:]...)
+		}
+	}
+
+	return a
+}
+
+// ClrBits unsets several bits.
+func (a *RMask) ClrBits(bits ...int) *RMask {
+	for i := 0; i < len(bits); i++ {
+		a.ClrBit(bits[i])
+	}
+
+	return a
+}
+
+// Copy returns a copy of a bitmask.
+func (a *RMask) Copy() *RMask {
+	b := &RMask{
+		bitCap: a.bitCap,
+		keys:   append(make([]int, 0, len(a.keys)), a.keys...),
+		conts:  make([]container, 0, len(a.conts)),
+	}
+
+	for _, c := range a.conts {
+		b.conts = append(b.conts, c.clone())
+	}
+
+	return b
+}
+
+// Count returns the number of bits set.
+func (a *RMask) Count() int {
+	var c int
+	for i := 0; i < len(a.conts); i++ {
+		c += a.conts[i].count()
+	}
+
+	return c
+}
+
+// Equals determines if two bitmasks are equal. Equality is defined as
+// having the same bit capacity and the same bits set.
+func (a *RMask) Equals(b *RMask) bool {
+	if a == b {
+		return true
+	}
+
+	if a.bitCap != b.bitCap || len(a.keys) != len(b.keys) {
+		return false
+	}
+
+	for i := 0; i < len(a.keys); i++ {
+		if a.keys[i] != b.keys[i] || !equal(a.conts[i], b.conts[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LMask returns an equivalent uncompressed bitmask.
+func (a *RMask) LMask() *lmask.LMask {
+	m := lmask.Zero(a.bitCap)
+	for i, c := range a.conts {
+		k := a.keys[i] << chunkBits
+		for x := c.next(0); 0 <= x; x = c.next(x + 1) {
+			m.SetBit(k | x)
+		}
+	}
+
+	return m
+}
+
+// MasksBit determines if a bit is set in a. Bits not on range
+// [0, bitCap) are never set.
+func (a *RMask) MasksBit(bit int) bool {
+	if bit < 0 || a.bitCap <= bit {
+		return false
+	}
+
+	k := bit >> chunkBits
+	i := a.search(k)
+	return i < len(a.keys) && a.keys[i] == k && a.conts[i].contains(uint16(bit))
+}
+
+// NextBit returns the next set bit in a. If no set bit is next, then
+// the bit capacity is returned.
+func (a *RMask) NextBit(bit int) int {
+	bit = clamp(bit+1, 0, a.bitCap)
+	k := bit >> chunkBits
+	for i := a.search(k); i < len(a.keys); i++ {
+		var x int
+		if a.keys[i] == k {
+			x = bit & chunkMax
+		}
+
+		if x = a.conts[i].next(x); 0 <= x {
+			return min(a.keys[i]<<chunkBits|x, a.bitCap)
+		}
+	}
+
+	return a.bitCap
+}
+
+// Optimize converts each container to its smallest representation,
+// which may be a run container for bitmasks having long runs of set
+// bits. Containers are not converted to run containers otherwise. A run
+// container fragmented by later changes into more runs than would fit in
+// the size of a bitmap container is converted back.
+func (a *RMask) Optimize() *RMask {
+	for i := 0; i < len(a.conts); i++ {
+		a.conts[i] = optimize(a.conts[i])
+	}
+
+	return a
+}
+
+// PrevBit returns the previous set bit in a. If no set bit is next,
+// then -1 is returned.
+func (a *RMask) PrevBit(bit int) int {
+	bit = clamp(bit, 0, a.bitCap) - 1
+	if bit < 0 {
+		return -1
+	}
+
+	k := bit >> chunkBits
+	for i := sort.Search(len(a.keys), func(i int) bool { return k < a.keys[i] }) - 1; 0 <= i; i-- {
+		x := chunkMax
+		if a.keys[i] == k {
+			x = bit & chunkMax
+		}
+
+		if x = a.conts[i].prev(x); 0 <= x {
+			return a.keys[i]<<chunkBits | x
+		}
+	}
+
+	return -1
+}
+
+// SetBit sets a bit in a. If the bit is not on range [0, bitCap), then
+// SetBit panics with an error wrapping lmask.ErrBitOutOfRange.
+func (a *RMask) SetBit(bit int) *RMask {
+	if bit < 0 || a.bitCap <= bit {
+		panic(bitOutOfRange(a, bit))
+	}
+
+	k := bit >> chunkBits
+	if n := len(a.keys); 0 < n && a.keys[n-1] == k {
+		// Fast path for bits set in increasing order.
+		a.conts[n-1] = a.conts[n-1].add(uint16(bit))
+		return a
+	}
+
+	i := a.search(k)
+	if i < len(a.keys) && a.keys[i] == k {
+		a.conts[i] = a.conts[i].add(uint16(bit))
+		return a
+	}
+
+	a.keys = append(a.keys, 0)
+	copy(a.keys[i+1:], a.keys[i:])
+	a.keys[i] = k
+
+	a.conts = append(a.conts, nil)
+	copy(a.conts[i+1:], a.conts[i:])
+	a.conts[i] = arrayContainer{uint16(bit)}
+	return a
+}
+
+// SetBits sets several bits.
+func (a *RMask) SetBits(bits ...int) *RMask {
+	for i := 0; i < len(bits); i++ {
+		a.SetBit(bits[i])
+	}
+
+	return a
+}
+
+// --------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------
+
+// clamp returns a if n < a, b if b < n, or otherwise n.
+func clamp(n, a, b int) int {
+	switch {
+	case n < a:
+		return a
+	case b < n:
+		return b
+	default:
+		return n
+	}
+}
+
+// bitOutOfRange returns an error indicating a bit is not on range
+// [0, bitCap).
+func bitOutOfRange(a *RMask, bit int) error {
+	return fmt.Errorf("%w: %d not on range [0, %d)", lmask.ErrBitOutOfRange, bit, a.bitCap)
+}
+
+// uneqBitCaps returns an error indicating two bitmasks do not have the
+// same bit capacity.
+func uneqBitCaps(a, b *RMask) error {
+	return fmt.Errorf("%w: %d and %d", lmask.ErrUnequalBitCaps, a.bitCap, b.bitCap)
+}
+
+// search returns the index of the first key greater than or equal to k.
+func (a *RMask) search(k int) int {
+	return sort.Search(len(a.keys), func(i int) bool { return k <= a.keys[i] })
+}
@@ -0,0 +1,248 @@
+package rmask
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/nathangreene3/bitmask/lmask"
+)
+
+const bitCap = 4 * chunkSize
+
+// -------------------------------------------------------------------------
+// Test data
+// -------------------------------------------------------------------------
+
+// sparse returns a bitmask stored in array containers.
+func sparse() *lmask.LMask {
+	return lmask.FromBits(bitCap, 0, 1, 2, chunkMax, chunkSize, 3*chunkSize+7, bitCap-1)
+}
+
+// dense returns a bitmask stored in bitmap containers.
+func dense(seed int64) *lmask.LMask {
+	var (
+		r = rand.New(rand.NewSource(seed))
+		m = lmask.Zero(bitCap)
+	)
+
+	for i := 0; i < 8*arrayMax; i++ {
+		m.SetBit(r.Intn(bitCap))
+	}
+
+	return m
+}
+
+// runs returns a bitmask having long runs of set bits.
+func runs() *lmask.LMask {
+	m := lmask.Zero(bitCap)
+	for _, r := range [][2]int{{0, 100}, {chunkMax - 10, chunkSize + 10}, {2 * chunkSize, 3 * chunkSize}, {bitCap - 5, bitCap}} {
+		for bit := r[0]; bit < r[1]; bit++ {
+			m.SetBit(bit)
+		}
+	}
+
+	return m
+}
+
+// every returns the bits on range [lo, hi) at a given step.
+func every(lo, hi, step int) []int {
+	var bits []int
+	for bit := lo; bit < hi; bit += step {
+		bits = append(bits, bit)
+	}
+
+	return bits
+}
+
+// masks returns several bitmasks covering each container type.
+func masks() []*lmask.LMask {
+	return []*lmask.LMask{lmask.Zero(bitCap), sparse(), dense(1), dense(2), runs(), lmask.Max(bitCap)}
+}
+
+// -------------------------------------------------------------------------
+// Tests
+// -------------------------------------------------------------------------
+
+func TestFromToLMask(t *testing.T) {
+	for _, exp := range append(masks(), lmask.Zero(0), lmask.FromBits(10, 1, 3)) {
+		a := FromLMask(exp)
+		if rec := a.LMask(); !exp.Equals(rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+		}
+
+		if rec := a.Copy().Optimize().LMask(); !exp.Equals(rec) {
+			t.Errorf("\nexpected %v\nreceived %v\n", exp, rec)
+		}
+
+		if exp, rec := exp.Count(), a.Count(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+	}
+}
+
+func TestContainers(t *testing.T) {
+	type testCase struct {
+		a   *RMask
+		exp string
+	}
+
+	kind := func(c container) string {
+		switch c.(type) {
+		case arrayContainer:
+			return "array"
+		case *bitmapContainer:
+			return "bitmap"
+		case runContainer:
+			return "run"
+		default:
+			return "nil"
+		}
+	}
+
+	tcs := []testCase{
+		{a: FromLMask(sparse()), exp: "array"},
+		{a: FromLMask(dense(1)), exp: "bitmap"},
+		{a: FromLMask(runs()).Optimize(), exp: "run"},
+		{a: FromLMask(lmask.Max(bitCap)).Optimize(), exp: "run"},
+		{a: FromLMask(dense(1)).And(FromLMask(sparse())), exp: "array"},
+		{a: FromLMask(dense(1)).ClrBits(FromLMask(dense(1)).Bits()[arrayMax:]...), exp: "array"},
+
+		// Fragmented runs are converted once they outgrow a bitmap.
+		{a: FromLMask(lmask.Max(bitCap)).Optimize().ClrBits(every(1, 2*runMax+3, 2)...), exp: "bitmap"},
+		{a: FromLMask(runs()).Optimize().SetBits(every(200, 200+2*runMax+1, 2)...), exp: "array"},
+		{a: FromLMask(runs()).Optimize().SetBits(every(200, 200+2*runMax-200, 2)...), exp: "run"},
+	}
+
+	for _, tc := range tcs {
+		if rec := kind(tc.a.conts[0]); tc.exp != rec {
+			t.Errorf("\nexpected %s\nreceived %s\n", tc.exp, rec)
+		}
+	}
+}
+
+func TestLogic(t *testing.T) {
+	type testCase struct {
+		name string
+		r    func(a, b *RMask) *RMask
+		l    func(a, b *lmask.LMask) *lmask.LMask
+	}
+
+	tcs := []testCase{
+		{name: "And", r: (*RMask).And, l: (*lmask.LMask).And},
+		{name: "AndNot", r: (*RMask).AndNot, l: (*lmask.LMask).AndNot},
+		{name: "Or", r: (*RMask).Or, l: (*lmask.LMask).Or},
+		{name: "XOr", r: (*RMask).XOr, l: (*lmask.LMask).XOr},
+	}
+
+	ms := masks()
+	for _, tc := range tcs {
+		for i := 0; i < len(ms); i++ {
+			for j := 0; j < len(ms); j++ {
+				for _, opt := range []bool{false, true} {
+					a, b := FromLMask(ms[i]), FromLMask(ms[j])
+					if opt {
+						a.Optimize()
+						b.Optimize()
+					}
+
+					exp := tc.l(ms[i].Copy(), ms[j])
+					if rec := tc.r(a, b).LMask(); !exp.Equals(rec) {
+						t.Errorf("\n%s(%d, %d): expected %d bits\nreceived %d bits\n", tc.name, i, j, exp.Count(), rec.Count())
+					}
+
+					if !FromLMask(ms[j]).Equals(b) {
+						t.Errorf("\n%s(%d, %d): expected operand to be unchanged\n", tc.name, i, j)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestNextPrevBit(t *testing.T) {
+	for _, m := range masks() {
+		for _, opt := range []bool{false, true} {
+			a := FromLMask(m)
+			if opt {
+				a.Optimize()
+			}
+
+			for bit := -1; bit <= bitCap; bit += 97 {
+				if exp, rec := m.NextBit(bit), a.NextBit(bit); exp != rec {
+					t.Errorf("\nexpected next bit after %d to be %d\nreceived %d\n", bit, exp, rec)
+				}
+
+				if exp, rec := m.PrevBit(bit), a.PrevBit(bit); exp != rec {
+					t.Errorf("\nexpected previous bit before %d to be %d\nreceived %d\n", bit, exp, rec)
+				}
+			}
+		}
+	}
+}
+
+func TestSetClrBit(t *testing.T) {
+	var (
+		r    = rand.New(rand.NewSource(0))
+		exp  = lmask.Zero(bitCap)
+		recs = []*RMask{Zero(bitCap), FromLMask(runs()).Optimize()}
+	)
+
+	recs[1].AndNot(FromLMask(runs()))
+	for i := 0; i < 4*arrayMax; i++ {
+		bit := r.Intn(bitCap)
+		if r.Intn(3) == 0 {
+			exp.ClrBit(bit)
+			for _, rec := range recs {
+				rec.ClrBit(bit)
+			}
+		} else {
+			exp.SetBit(bit)
+			for _, rec := range recs {
+				rec.SetBit(bit)
+			}
+		}
+	}
+
+	for _, rec := range recs {
+		if !exp.Equals(rec.LMask()) {
+			t.Errorf("\nexpected %d bits\nreceived %d bits\n", exp.Count(), rec.Count())
+		}
+
+		for bit := 0; bit < bitCap; bit += 13 {
+			if exp.MasksBit(bit) != rec.MasksBit(bit) {
+				t.Errorf("\nexpected bit %d masked to be %t\n", bit, exp.MasksBit(bit))
+			}
+		}
+	}
+
+	// Runs are split and joined in place.
+	rec := FromLMask(runs()).Optimize().ClrBits(50, 51, 2*chunkSize).SetBits(51, 50, 2*chunkSize)
+	if exp := runs(); !exp.Equals(rec.LMask()) {
+		t.Errorf("\nexpected %d bits\nreceived %d bits\n", exp.Count(), rec.Count())
+	}
+}
+
+func TestBits(t *testing.T) {
+	for _, m := range masks() {
+		exp, rec := m.Bits(), FromLMask(m).Bits()
+		equal := len(exp) == len(rec)
+		for i := 0; i < len(exp) && equal; i++ {
+			equal = exp[i] == rec[i]
+		}
+
+		if !equal {
+			t.Errorf("\nexpected %d bits\nreceived %d bits\n", len(exp), len(rec))
+		}
+	}
+}
+
+// -------------------------------------------------------------------------
+// Benchmarks
+// -------------------------------------------------------------------------
+
+func BenchmarkOr(b *testing.B) {
+	x, y := FromLMask(dense(1)), FromLMask(sparse())
+	for i := 0; i < b.N; i++ {
+		x.Copy().Or(y)
+	}
+}
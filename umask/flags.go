@@ -0,0 +1,187 @@
+package umask
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// flagSep separates flag names in a formatted bitmask.
+const flagSep = "|"
+
+var (
+	// ErrInvalidFlag indicates a flag name cannot be defined in a flag
+	// set.
+	ErrInvalidFlag = errors.New("invalid flag name")
+
+	// ErrUnknownFlag indicates a flag name has not been defined in a flag
+	// set.
+	ErrUnknownFlag = errors.New("unknown flag")
+)
+
+// FlagSet assigns names to bit positions so a UMask may be used as a
+// set of named flags.
+type FlagSet struct {
+	names []string
+	bits  map[string]int
+}
+
+// NewFlagSet returns a flag set naming bits in order, beginning with
+// bit zero. An empty name leaves its bit undefined. NewFlagSet panics
+// with an error wrapping ErrInvalidFlag if a name is repeated, if a name
+// is rejected by CheckFlagName, or if there are more names than the bit
+// capacity.
+func NewFlagSet(names ...string) *FlagSet {
+	if BitCap < len(names) {
+		panic(fmt.Errorf("%w: %d flags exceeds bit capacity %d", ErrInvalidFlag, len(names), BitCap))
+	}
+
+	f := &FlagSet{
+		names: append(make([]string, 0, len(names)), names...),
+		bits:  make(map[string]int, len(names)),
+	}
+
+	for bit, name := range names {
+		if name == "" {
+			continue
+		}
+
+		if _, ok := f.bits[name]; ok {
+			panic(fmt.Errorf("%w: %q defined more than once", ErrInvalidFlag, name))
+		}
+
+		if err := CheckFlagName(name); err != nil {
+			panic(err)
+		}
+
+		f.bits[name] = bit
+	}
+
+	return f
+}
+
+// CheckFlagName returns an error wrapping ErrInvalidFlag if a name
+// cannot be defined in a flag set. A name is invalid if it is empty,
+// contains the separator "|" or whitespace, or is a number, as Parse
+// could never match it.
+func CheckFlagName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: empty", ErrInvalidFlag)
+	case strings.Contains(name, flagSep):
+		return fmt.Errorf("%w: %q contains %q", ErrInvalidFlag, name, flagSep)
+	case strings.IndexFunc(name, unicode.IsSpace) != -1:
+		return fmt.Errorf("%w: %q contains whitespace", ErrInvalidFlag, name)
+	}
+
+	if _, err := strconv.Atoi(name); err == nil {
+		return fmt.Errorf("%w: %q is a number", ErrInvalidFlag, name)
+	}
+
+	return nil
+}
+
+// Bit returns the bit a name is assigned to. If the name is not
+// defined, then -1 and false are returned.
+func (f *FlagSet) Bit(name string) (int, bool) {
+	bit, ok := f.bits[name]
+	if !ok {
+		return -1, false
+	}
+
+	return bit, true
+}
+
+// Defined returns a bitmask with each named bit set.
+func (f *FlagSet) Defined() UMask {
+	var a UMask
+	for _, bit := range f.bits {
+		a = a.SetBit(bit)
+	}
+
+	return a
+}
+
+// Flag returns a bitmask with only the bit of a given name set. Flag
+// panics if the name is not defined.
+func (f *FlagSet) Flag(name string) UMask {
+	bit, ok := f.bits[name]
+	if !ok {
+		panic(fmt.Errorf("%w: %q", ErrUnknownFlag, name))
+	}
+
+	return One << bit
+}
+
+// Fmt returns the names of the bits set in a bitmask separated by "|",
+// such as "read|exec". Undefined bits are formatted as their bit
+// position. The zero bitmask is formatted as the empty string.
+func (f *FlagSet) Fmt(a UMask) string {
+	var sb strings.Builder
+	for bit := range a.All() {
+		if 0 < sb.Len() {
+			sb.WriteString(flagSep)
+		}
+
+		sb.WriteString(f.Name(bit))
+	}
+
+	return sb.String()
+}
+
+// Name returns the name of a bit. If the bit is not named, then the bit
+// position is returned.
+func (f *FlagSet) Name(bit int) string {
+	if 0 <= bit && bit < len(f.names) && f.names[bit] != "" {
+		return f.names[bit]
+	}
+
+	return strconv.Itoa(bit)
+}
+
+// Names returns the defined names in order of their bits.
+func (f *FlagSet) Names() []string {
+	names := make([]string, 0, len(f.bits))
+	for _, name := range f.names {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Parse returns a bitmask from names separated by "|" as formatted by
+// Fmt. Surrounding whitespace is ignored and bit positions are accepted
+// in place of names. An error wrapping ErrUnknownFlag is returned for
+// any name that is not defined.
+func (f *FlagSet) Parse(s string) (UMask, error) {
+	var a UMask
+	if strings.TrimSpace(s) == "" {
+		return a, nil
+	}
+
+	for _, name := range strings.Split(s, flagSep) {
+		name = strings.TrimSpace(name)
+		if bit, ok := f.bits[name]; ok {
+			a = a.SetBit(bit)
+			continue
+		}
+
+		if bit, err := strconv.Atoi(name); err == nil && 0 <= bit && bit < BitCap {
+			a = a.SetBit(bit)
+			continue
+		}
+
+		return Zero, fmt.Errorf("%w: %q", ErrUnknownFlag, name)
+	}
+
+	return a, nil
+}
+
+// Undefined returns the bits set in a bitmask that are not named.
+func (f *FlagSet) Undefined(a UMask) UMask {
+	return a.AndNot(f.Defined())
+}
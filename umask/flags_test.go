@@ -0,0 +1,104 @@
+package umask
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestFlagSet(t *testing.T) {
+	type testCase struct {
+		a   UMask
+		exp string
+	}
+
+	var (
+		perms = NewFlagSet("read", "write", "", "exec")
+		tcs   = []testCase{
+			{a: Zero, exp: ""},
+			{a: Zero.SetBits(0), exp: "read"},
+			{a: Zero.SetBits(0, 3), exp: "read|exec"},
+			{a: Zero.SetBits(0, 1, 3), exp: "read|write|exec"},
+			{a: Zero.SetBits(1, 2, BitCap-1), exp: "write|2|" + perms.Name(BitCap-1)},
+		}
+	)
+
+	for _, tc := range tcs {
+		if rec := perms.Fmt(tc.a); tc.exp != rec {
+			t.Errorf("\nexpected %q\nreceived %q\n", tc.exp, rec)
+		}
+
+		if rec, err := perms.Parse(tc.exp); err != nil {
+			t.Error(err)
+		} else if tc.a != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", tc.a, rec)
+		}
+	}
+
+	if rec, err := perms.Parse(" exec | read "); err != nil || rec != perms.Flag("read")|perms.Flag("exec") {
+		t.Errorf("\nexpected %d\nreceived %d, %v\n", perms.Flag("read")|perms.Flag("exec"), rec, err)
+	}
+
+	for _, s := range []string{"delete", "read|", "read|delete", "-1"} {
+		if _, err := perms.Parse(s); !errors.Is(err, ErrUnknownFlag) {
+			t.Errorf("\nexpected %v for %q\nreceived %v\n", ErrUnknownFlag, s, err)
+		}
+	}
+
+	if exp, rec := []string{"read", "write", "exec"}, perms.Names(); !slices.Equal(exp, rec) {
+		t.Errorf("\nexpected %q\nreceived %q\n", exp, rec)
+	}
+
+	if exp, rec := Zero.SetBits(0, 1, 3), perms.Defined(); exp != rec {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+
+	if exp, rec := Zero.SetBits(2, 4), perms.Undefined(Zero.SetBits(0, 2, 3, 4)); exp != rec {
+		t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+	}
+
+	if bit, ok := perms.Bit("exec"); bit != 3 || !ok {
+		t.Errorf("\nexpected 3, true\nreceived %d, %t\n", bit, ok)
+	}
+
+	if bit, ok := perms.Bit(""); bit != -1 || ok {
+		t.Errorf("\nexpected -1, false\nreceived %d, %t\n", bit, ok)
+	}
+}
+
+func TestCheckFlagName(t *testing.T) {
+	for _, name := range []string{"", "a|b", "a b", "a\t", "12", "-3"} {
+		if err := CheckFlagName(name); !errors.Is(err, ErrInvalidFlag) {
+			t.Errorf("\nexpected %v for %q\nreceived %v\n", ErrInvalidFlag, name, err)
+		}
+	}
+
+	for _, name := range []string{"read", "x1", "été"} {
+		if err := CheckFlagName(name); err != nil {
+			t.Errorf("\nexpected no error for %q\nreceived %v\n", name, err)
+		}
+	}
+}
+
+func TestNewFlagSetPanics(t *testing.T) {
+	tcs := [][]string{
+		{"read", "read"},
+		{"read|write"},
+		{"1"},
+		{"read write"},
+		{" read"},
+		make([]string, BitCap+1),
+	}
+
+	for _, names := range tcs {
+		func() {
+			defer func() {
+				if err, _ := recover().(error); !errors.Is(err, ErrInvalidFlag) {
+					t.Errorf("\nexpected %v for %q\nreceived %v\n", ErrInvalidFlag, names, err)
+				}
+			}()
+
+			NewFlagSet(names...)
+		}()
+	}
+}
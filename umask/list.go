@@ -0,0 +1,107 @@
+package umask
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/nathangreene3/bitmask/internal/bounds"
+	"github.com/nathangreene3/bitmask/internal/list"
+)
+
+var (
+	// ErrBitOutOfRange indicates a bit is not on range [0, BitCap). It is
+	// the same error as lmask.ErrBitOutOfRange. Every method taking a
+	// range of bits [lo, hi) panics with it if the range is non-empty and
+	// not within [0, BitCap), or [0, a.BitCap()) for a Mask. An empty
+	// range, where hi <= lo, is always allowed.
+	ErrBitOutOfRange = bounds.ErrBitOutOfRange
+
+	// ErrSyntax indicates text is not a valid list or hex mask.
+	ErrSyntax = list.ErrSyntax
+)
+
+// ParseError describes a failure to parse a list or hex mask. The
+// position is the byte offset into the text at which parsing failed.
+// The error wraps ErrSyntax or ErrBitOutOfRange.
+type ParseError = list.ParseError
+
+// ParseList returns a bitmask from a list of bits and inclusive ranges
+// of bits separated by commas, such as "0-3,8,10-15". This is the format
+// Linux uses for cpu lists. Surrounding whitespace is ignored and the
+// empty list is the zero bitmask. A *ParseError is returned if the text
+// is invalid or a bit is not on range [0, BitCap).
+func ParseList(s string) (UMask, error) {
+	var a UMask
+	err := list.Parse(s, func(lo, hi int) error {
+		if BitCap <= hi {
+			return fmt.Errorf("%w: %d not on range [0, %d)", ErrBitOutOfRange, hi, BitCap)
+		}
+
+		for bit := lo; bit <= hi; bit++ {
+			a = a.SetBit(bit)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return Zero, err
+	}
+
+	return a, nil
+}
+
+// FormatList returns the set bits as a list of bits and inclusive
+// ranges of bits separated by commas, such as "0-3,8,10-15". The zero
+// bitmask is formatted as the empty string.
+func (a UMask) FormatList() string {
+	var b []byte
+	for lo := a.NextBit(-1); lo < BitCap; {
+		hi := lo
+		for next := a.NextBit(hi); next == hi+1 && next < BitCap; next = a.NextBit(hi) {
+			hi = next
+		}
+
+		b = list.AppendRange(b, lo, hi)
+		lo = a.NextBit(hi)
+	}
+
+	return string(b)
+}
+
+// ParseHexMask returns a bitmask from 32-bit groups of hex digits
+// separated by commas, most significant group first, such as
+// "00000000,0000ff0f". This is the format Linux uses for cpu masks.
+// Groups may have fewer than eight digits and surrounding whitespace is
+// ignored. A *ParseError is returned if the text is invalid or a bit is
+// not on range [0, BitCap).
+func ParseHexMask(s string) (UMask, error) {
+	var a UMask
+	err := list.ParseHex(s, func(i int, group uint32) error {
+		if group == 0 {
+			return nil
+		}
+
+		if bit := i*32 + 31 - bits.LeadingZeros32(group); BitCap <= bit {
+			return fmt.Errorf("%w: %d not on range [0, %d)", ErrBitOutOfRange, bit, BitCap)
+		}
+
+		a |= UMask(group) << (i * 32)
+		return nil
+	})
+
+	if err != nil {
+		return Zero, err
+	}
+
+	return a, nil
+}
+
+// FormatHexMask returns the bitmask as 32-bit groups of eight hex digits
+// separated by commas, most significant group first, such as
+// "00000000,0000ff0f". There is one group for every 32 bits of capacity.
+func (a UMask) FormatHexMask() string {
+	return list.FormatHex(BitCap/32, func(i int) uint32 {
+		return uint32(a >> (i * 32))
+	})
+}
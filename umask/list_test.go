@@ -0,0 +1,104 @@
+package umask
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nathangreene3/bitmask/lmask"
+)
+
+func TestList(t *testing.T) {
+	type testCase struct {
+		a   UMask
+		exp string
+	}
+
+	tcs := []testCase{
+		{a: Zero, exp: ""},
+		{a: One, exp: "0"},
+		{a: Zero.SetBits(0, 1, 2, 3, 8, 10, 11, 12, 13, 14, 15), exp: "0-3,8,10-15"},
+		{a: Zero.SetBits(BitCap-2, BitCap-1), exp: fmt.Sprintf("%d-%d", BitCap-2, BitCap-1)},
+		{a: Max, exp: fmt.Sprintf("0-%d", BitCap-1)},
+	}
+
+	for _, tc := range tcs {
+		rec := tc.a.FormatList()
+		if tc.exp != rec {
+			t.Errorf("\nexpected %q\nreceived %q\n", tc.exp, rec)
+		}
+
+		b, err := ParseList(rec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if tc.a != b {
+			t.Errorf("\nexpected %d\nreceived %d\n", tc.a, b)
+		}
+	}
+}
+
+func TestParseListError(t *testing.T) {
+	type testCase struct {
+		s   string
+		pos int
+		err error
+	}
+
+	tcs := []testCase{
+		{s: fmt.Sprintf("0,%d", BitCap), pos: 2, err: ErrBitOutOfRange},
+		{s: "0,1-", pos: 4, err: ErrSyntax},
+		{s: "a", pos: 0, err: ErrSyntax},
+	}
+
+	for _, tc := range tcs {
+		_, err := ParseList(tc.s)
+		var pe *ParseError
+		if !errors.Is(err, tc.err) || !errors.As(err, &pe) || tc.pos != pe.Pos {
+			t.Errorf("\nexpected %v at position %d\nreceived %v\n", tc.err, tc.pos, err)
+		}
+	}
+}
+
+func TestHexMask(t *testing.T) {
+	type testCase struct {
+		a   UMask
+		exp string
+	}
+
+	groups := strings.Repeat("00000000,", BitCap/32-1)
+	tcs := []testCase{
+		{a: Zero, exp: groups + "00000000"},
+		{a: Zero.SetBits(0, 1, 2, 3, 8, 10, 11, 12, 13, 14, 15), exp: groups + "0000fd0f"},
+		{a: Max, exp: strings.Repeat("ffffffff,", BitCap/32-1) + "ffffffff"},
+	}
+
+	for _, tc := range tcs {
+		rec := tc.a.FormatHexMask()
+		if tc.exp != rec {
+			t.Errorf("\nexpected %q\nreceived %q\n", tc.exp, rec)
+		}
+
+		b, err := ParseHexMask(rec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if tc.a != b {
+			t.Errorf("\nexpected %d\nreceived %d\n", tc.a, b)
+		}
+	}
+
+	if _, err := ParseHexMask("1," + groups + "0"); !errors.Is(err, ErrBitOutOfRange) {
+		t.Errorf("\nexpected %v\nreceived %v\n", ErrBitOutOfRange, err)
+	}
+
+	// Out of range errors match across the bitmask packages.
+	if _, err := ParseList(fmt.Sprint(BitCap)); !errors.Is(err, lmask.ErrBitOutOfRange) {
+		t.Errorf("\nexpected %v\nreceived %v\n", lmask.ErrBitOutOfRange, err)
+	}
+}
@@ -0,0 +1,255 @@
+package umask
+
+import (
+	"iter"
+	"math/bits"
+	"strconv"
+)
+
+// Unsigned is a constraint permitting any unsigned integer type of fixed
+// width that a Mask may be backed by.
+type Unsigned interface {
+	~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uint
+}
+
+// Mask is an implementation of a bitmask backed by an unsigned integer
+// type. Unlike UMask, the bit capacity is the width of T and does not
+// depend on the platform unless T is uint.
+type Mask[T Unsigned] struct {
+	w T
+}
+
+// Mask8 is a bitmask having a bit capacity of 8.
+type Mask8 = Mask[uint8]
+
+// Mask16 is a bitmask having a bit capacity of 16.
+type Mask16 = Mask[uint16]
+
+// Mask32 is a bitmask having a bit capacity of 32.
+type Mask32 = Mask[uint32]
+
+// Mask64 is a bitmask having a bit capacity of 64.
+type Mask64 = Mask[uint64]
+
+// -------------------------------------------------------------------------
+// Constructors
+// -------------------------------------------------------------------------
+
+// MaskOf returns a bitmask having the bits of a given word set.
+func MaskOf[T Unsigned](w T) Mask[T] {
+	return Mask[T]{w: w}
+}
+
+// MaxMask returns a bitmask with all bits set.
+func MaxMask[T Unsigned]() Mask[T] {
+	return Mask[T]{w: ^T(0)}
+}
+
+// -------------------------------------------------------------------------
+// Bitwise functionality
+// -------------------------------------------------------------------------
+
+// And returns a bitmask with only the bits set that are common to both bitmasks.
+func (a Mask[T]) And(b Mask[T]) Mask[T] {
+	return Mask[T]{w: a.w & b.w}
+}
+
+// AndNot returns a bitmask with the bits set in a and the bits not set in b.
+func (a Mask[T]) AndNot(b Mask[T]) Mask[T] {
+	return Mask[T]{w: a.w &^ b.w}
+}
+
+// NAnd returns a bitmask with the bits set that are not set in both a and b.
+func (a Mask[T]) NAnd(b Mask[T]) Mask[T] {
+	return Mask[T]{w: ^(a.w & b.w)}
+}
+
+// NOr returns a bitmask with the bits set that are set in neither a nor b.
+func (a Mask[T]) NOr(b Mask[T]) Mask[T] {
+	return Mask[T]{w: ^(a.w | b.w)}
+}
+
+// Not inverts a bitmask.
+func (a Mask[T]) Not() Mask[T] {
+	return Mask[T]{w: ^a.w}
+}
+
+// Or returns a bitmask with the bits set in either a or b.
+func (a Mask[T]) Or(b Mask[T]) Mask[T] {
+	return Mask[T]{w: a.w | b.w}
+}
+
+// XNOr returns a bitmask with the bits set that are either set or unset in both a and b.
+func (a Mask[T]) XNOr(b Mask[T]) Mask[T] {
+	return Mask[T]{w: ^(a.w ^ b.w)}
+}
+
+// XOr returns the bits of a and b that are set, but not simultaneously set in both a and b.
+func (a Mask[T]) XOr(b Mask[T]) Mask[T] {
+	return Mask[T]{w: a.w ^ b.w}
+}
+
+// -------------------------------------------------------------------------
+// Set functionality
+// -------------------------------------------------------------------------
+
+// All returns an iterator over the set bits in increasing order.
+func (a Mask[T]) All() iter.Seq[int] {
+	return a.AllRange(0, a.BitCap())
+}
+
+// AllRange returns an iterator over the set bits on range [lo, hi) in increasing order.
+func (a Mask[T]) AllRange(lo, hi int) iter.Seq[int] {
+	if err := checkRange(lo, hi, a.BitCap()); err != nil {
+		panic(err)
+	}
+
+	return func(yield func(int) bool) {
+		if hi <= lo {
+			return
+		}
+
+		for bit := a.NextBit(lo - 1); bit < hi; bit = a.NextBit(bit) {
+			if !yield(bit) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the set bits in decreasing order.
+func (a Mask[T]) Backward() iter.Seq[int] {
+	return a.BackwardRange(0, a.BitCap())
+}
+
+// BackwardRange returns an iterator over the set bits on range [lo, hi) in decreasing order.
+func (a Mask[T]) BackwardRange(lo, hi int) iter.Seq[int] {
+	if err := checkRange(lo, hi, a.BitCap()); err != nil {
+		panic(err)
+	}
+
+	return func(yield func(int) bool) {
+		if hi <= lo {
+			return
+		}
+
+		for bit := a.PrevBit(hi); lo <= bit; bit = a.PrevBit(bit) {
+			if !yield(bit) {
+				return
+			}
+		}
+	}
+}
+
+// BitCap returns the maximum number of bits in a bitmask, which is the width of T.
+func (a Mask[T]) BitCap() int {
+	return bits.OnesCount64(uint64(^T(0)))
+}
+
+// BitLen returns the minimum number of bits representing a.
+func (a Mask[T]) BitLen() int {
+	return bits.Len64(uint64(a.w))
+}
+
+// Bits returns the bits that are set in a bitmask.
+func (a Mask[T]) Bits() []int {
+	bitCap := a.BitCap()
+	bits := make([]int, 0, a.Count())
+	for bit := a.NextBit(-1); bit < bitCap; bit = a.NextBit(bit) {
+		bits = append(bits, bit)
+	}
+
+	return bits
+}
+
+// Clr returns a bitmask with the bits of a given bitmask cleared.
+func (a Mask[T]) Clr(b Mask[T]) Mask[T] {
+	return Mask[T]{w: a.w &^ b.w}
+}
+
+// ClrBit returns a bitmask with the given bit cleared.
+func (a Mask[T]) ClrBit(bit int) Mask[T] {
+	return Mask[T]{w: a.w &^ (1 << bit)}
+}
+
+// ClrBits returns a bitmask with a given number of bits unset.
+func (a Mask[T]) ClrBits(bits ...int) Mask[T] {
+	for i := 0; i < len(bits); i++ {
+		a.w &^= 1 << bits[i]
+	}
+
+	return a
+}
+
+// Count returns the number of bits set in a bitmask.
+func (a Mask[T]) Count() int {
+	return bits.OnesCount64(uint64(a.w))
+}
+
+// Fmt returns a representation of a bitmask in a given base on range [2, 36].
+func (a Mask[T]) Fmt(base int) string {
+	return strconv.FormatUint(uint64(a.w), base)
+}
+
+// LSh returns a bitmask with all bits shifted to the left a given number of bits.
+func (a Mask[T]) LSh(bits int) Mask[T] {
+	return Mask[T]{w: a.w << bits}
+}
+
+// Masks determines if the bits set in b are set in a.
+func (a Mask[T]) Masks(b Mask[T]) bool {
+	return a.w&b.w == b.w
+}
+
+// MasksBit determines if a bit is set.
+func (a Mask[T]) MasksBit(bit int) bool {
+	b := T(1) << bit
+	return a.w&b == b
+}
+
+// NextBit returns the next set bit. If there is no next set bit, then the bit capacity is returned.
+func (a Mask[T]) NextBit(bit int) int {
+	bitCap := a.BitCap()
+	bit = clamp(bit+1, 0, bitCap)
+	return min(bits.TrailingZeros64(uint64(a.w)>>bit<<bit), bitCap)
+}
+
+// PrevBit returns the previous set bit. If there is no previous set bit, then -1 is returned.
+func (a Mask[T]) PrevBit(bit int) int {
+	bit = clamp(bit, 0, a.BitCap())
+	return bits.Len64(uint64(a.w)&(1<<bit-1)) - 1
+}
+
+// RSh returns a bitmask with all bits shifted to the right a given number of bits.
+func (a Mask[T]) RSh(bits int) Mask[T] {
+	return Mask[T]{w: a.w >> bits}
+}
+
+// Set returns a bitmask with bits set in a or b.
+func (a Mask[T]) Set(b Mask[T]) Mask[T] {
+	return Mask[T]{w: a.w | b.w}
+}
+
+// SetBit sets a bit in a bitmask.
+func (a Mask[T]) SetBit(bit int) Mask[T] {
+	return Mask[T]{w: a.w | (1 << bit)}
+}
+
+// SetBits sets several bits in a bitmask.
+func (a Mask[T]) SetBits(bits ...int) Mask[T] {
+	for i := 0; i < len(bits); i++ {
+		a.w |= 1 << bits[i]
+	}
+
+	return a
+}
+
+// String returns the base-10 representation of a bitmask.
+func (a Mask[T]) String() string {
+	return a.Fmt(10)
+}
+
+// Word returns the unsigned integer backing a bitmask.
+func (a Mask[T]) Word() T {
+	return a.w
+}
@@ -0,0 +1,179 @@
+package umask
+
+import (
+	"errors"
+	"iter"
+	"math/bits"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestMaskBitCap(t *testing.T) {
+	type testCase struct {
+		rec, exp int
+	}
+
+	tcs := []testCase{
+		{rec: Mask8{}.BitCap(), exp: 8},
+		{rec: Mask16{}.BitCap(), exp: 16},
+		{rec: Mask32{}.BitCap(), exp: 32},
+		{rec: Mask64{}.BitCap(), exp: 64},
+		{rec: Mask[uint]{}.BitCap(), exp: BitCap},
+	}
+
+	for _, tc := range tcs {
+		if tc.exp != tc.rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", tc.exp, tc.rec)
+		}
+	}
+}
+
+func TestMask(t *testing.T) {
+	testMask[uint8](t)
+	testMask[uint16](t)
+	testMask[uint32](t)
+	testMask[uint64](t)
+	testMask[uint](t)
+}
+
+// testMask tests a bitmask of a given width against its word and, if
+// UMask is wide enough, against an equivalent UMask.
+func testMask[T Unsigned](t *testing.T) {
+	var (
+		bitCap = Mask[T]{}.BitCap()
+		tcs    = []Mask[T]{
+			{},
+			MaskOf[T](1),
+			MaxMask[T](),
+			Mask[T]{}.SetBits(0, bitCap-1),
+			MaxMask[T]().ClrBits(0, bitCap-1),
+			Mask[T]{}.SetBits(1, 3, 5, bitCap-3),
+		}
+	)
+
+	for _, a := range tcs {
+		// Each bitmask is checked against its word widened to 64 bits.
+		w := uint64(a.Word())
+		var expBits []int
+		for v := w; v != 0; v &= v - 1 {
+			expBits = append(expBits, bits.TrailingZeros64(v))
+		}
+
+		if rec := a.Bits(); !slices.Equal(expBits, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", expBits, rec)
+		}
+
+		if exp, rec := bits.OnesCount64(w), a.Count(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		if exp, rec := bits.Len64(w), a.BitLen(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		if exp, rec := strconv.FormatUint(w, 2), a.Fmt(2); exp != rec {
+			t.Errorf("\nexpected %s\nreceived %s\n", exp, rec)
+		}
+
+		if exp, rec := ^a.Word(), a.Not().Word(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		for bit := 0; bit < bitCap; bit++ {
+			if exp, rec := w>>bit&1 == 1, a.MasksBit(bit); exp != rec {
+				t.Errorf("\nexpected %t\nreceived %t\n", exp, rec)
+			}
+		}
+
+		if rec := a.NextBit(bitCap - 1); bitCap != rec {
+			t.Errorf("\nexpected next bit %d\nreceived %d\n", bitCap, rec)
+		}
+
+		if rec := slices.Collect(a.AllRange(0, bitCap)); !slices.Equal(expBits, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", expBits, rec)
+		}
+
+		for _, r := range [][2]int{{-5, -10}, {bitCap + 5, bitCap + 2}} {
+			if rec := slices.Collect(a.AllRange(r[0], r[1])); len(rec) != 0 {
+				t.Errorf("\nexpected no bits on [%d, %d)\nreceived %d\n", r[0], r[1], rec)
+			}
+
+			if rec := slices.Collect(a.BackwardRange(r[0], r[1])); len(rec) != 0 {
+				t.Errorf("\nexpected no bits on [%d, %d)\nreceived %d\n", r[0], r[1], rec)
+			}
+		}
+
+		for _, r := range [][2]int{{-1, bitCap}, {0, bitCap + 1}} {
+			for _, f := range []func(lo, hi int) iter.Seq[int]{a.AllRange, a.BackwardRange} {
+				func() {
+					defer func() {
+						if err, _ := recover().(error); !errors.Is(err, ErrBitOutOfRange) {
+							t.Errorf("\nexpected %v on [%d, %d)\nreceived %v\n", ErrBitOutOfRange, r[0], r[1], err)
+						}
+					}()
+
+					f(r[0], r[1])
+				}()
+			}
+		}
+
+		if BitCap < bitCap {
+			// UMask cannot represent T on this platform.
+			continue
+		}
+
+		u := UMask(a.Word())
+		if exp, rec := u.Bits(), a.Bits(); !slices.Equal(exp, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		if exp, rec := u.Count(), a.Count(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		if exp, rec := u.BitLen(), a.BitLen(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		if exp, rec := u.Fmt(2), a.Fmt(2); exp != rec {
+			t.Errorf("\nexpected %s\nreceived %s\n", exp, rec)
+		}
+
+		if exp, rec := T(^u), a.Not().Word(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		if exp, rec := T(u<<1), a.LSh(1).Word(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		if exp, rec := T(u)>>1, a.RSh(1).Word(); exp != rec {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		for bit := 0; bit < bitCap; bit++ {
+			if exp, rec := u.MasksBit(bit), a.MasksBit(bit); exp != rec {
+				t.Errorf("\nexpected %t\nreceived %t\n", exp, rec)
+			}
+
+			if exp, rec := min(u.NextBit(bit-1), bitCap), a.NextBit(bit-1); exp != rec {
+				t.Errorf("\nexpected next bit %d\nreceived %d\n", exp, rec)
+			}
+
+			if exp, rec := u.PrevBit(bit+1), a.PrevBit(bit+1); exp != rec {
+				t.Errorf("\nexpected previous bit %d\nreceived %d\n", exp, rec)
+			}
+		}
+
+		for _, b := range tcs {
+			if exp, rec := T(u.XNOr(UMask(b.Word()))), a.XNOr(b).Word(); exp != rec {
+				t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+			}
+
+			if exp, rec := u.Masks(UMask(b.Word())), a.Masks(b); exp != rec {
+				t.Errorf("\nexpected %t\nreceived %t\n", exp, rec)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package umask
+
+import (
+	"fmt"
+	"iter"
+)
+
+// Combinations returns an iterator over the bitmasks having exactly k of
+// the lowest n bits set, in increasing order. Each is found from the
+// last by Gosper's hack. If k is not on range [0, n], then there are no
+// combinations. Combinations panics with an error wrapping
+// ErrBitOutOfRange if n is not on range [0, BitCap].
+func Combinations(n, k int) iter.Seq[UMask] {
+	if n < 0 || BitCap < n {
+		panic(fmt.Errorf("%w: %d bits not on range [0, %d]", ErrBitOutOfRange, n, BitCap))
+	}
+
+	return func(yield func(UMask) bool) {
+		if k < 0 || n < k {
+			return
+		}
+
+		last := lowMask(k) << (n - k)
+		for s := lowMask(k); yield(s) && s != last; {
+			// Move the lowest run of set bits up by one and return all
+			// but one of its bits to the bottom.
+			c := s & -s
+			r := s + c
+			s = (r^s)>>2/c | r
+		}
+	}
+}
+
+// Submasks returns an iterator over the bitmasks having only bits set in
+// m, in decreasing order from m to zero.
+func Submasks(m UMask) iter.Seq[UMask] {
+	return func(yield func(UMask) bool) {
+		for s := m; yield(s) && s != 0; {
+			s = (s - 1) & m
+		}
+	}
+}
+
+// Supersets returns an iterator over the bitmasks having every bit set
+// in m and any of the lowest n bits set, in increasing order from m. If
+// m has a bit set that is not one of the lowest n bits, then there are
+// no supersets. Supersets panics with an error wrapping ErrBitOutOfRange
+// if n is not on range [0, BitCap].
+func Supersets(m UMask, n int) iter.Seq[UMask] {
+	if n < 0 || BitCap < n {
+		panic(fmt.Errorf("%w: %d bits not on range [0, %d]", ErrBitOutOfRange, n, BitCap))
+	}
+
+	return func(yield func(UMask) bool) {
+		last := lowMask(n)
+		if m&^last != 0 {
+			return
+		}
+
+		for s := m; yield(s) && s != last; {
+			s = (s + 1) | m
+		}
+	}
+}
+
+// lowMask returns a bitmask with the lowest n bits set, where n is on
+// range [0, BitCap].
+func lowMask(n int) UMask {
+	return ^(Max << n)
+}
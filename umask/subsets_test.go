@@ -0,0 +1,118 @@
+package umask
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCombinations(t *testing.T) {
+	type testCase struct {
+		n, k int
+		exp  []UMask
+	}
+
+	tcs := []testCase{
+		{n: 0, k: 0, exp: []UMask{0}},
+		{n: 3, k: 0, exp: []UMask{0}},
+		{n: 3, k: 4, exp: nil},
+		{n: 3, k: -1, exp: nil},
+		{n: 4, k: 2, exp: []UMask{0b0011, 0b0101, 0b0110, 0b1001, 0b1010, 0b1100}},
+		{n: 3, k: 3, exp: []UMask{0b111}},
+		{n: BitCap, k: BitCap, exp: []UMask{Max}},
+	}
+
+	for _, tc := range tcs {
+		if rec := slices.Collect(Combinations(tc.n, tc.k)); !slices.Equal(tc.exp, rec) {
+			t.Errorf("\nexpected %b\nreceived %b\n", tc.exp, rec)
+		}
+	}
+
+	// Every combination is counted once, including those using the
+	// highest bit.
+	for _, k := range []int{1, 2, 3} {
+		var count, exp int
+		for s := range Combinations(BitCap, k) {
+			if s.Count() != k {
+				t.Errorf("\nexpected %d bits set\nreceived %b\n", k, s)
+			}
+
+			count++
+		}
+
+		switch k {
+		case 1:
+			exp = BitCap
+		case 2:
+			exp = BitCap * (BitCap - 1) / 2
+		case 3:
+			exp = BitCap * (BitCap - 1) * (BitCap - 2) / 6
+		}
+
+		if exp != count {
+			t.Errorf("\nexpected %d combinations\nreceived %d\n", exp, count)
+		}
+	}
+}
+
+func TestSubmasksSupersets(t *testing.T) {
+	type testCase struct {
+		m             UMask
+		n             int
+		expSub, expSp []UMask
+	}
+
+	tcs := []testCase{
+		{m: 0, n: 0, expSub: []UMask{0}, expSp: []UMask{0}},
+		{m: 0b101, n: 3, expSub: []UMask{0b101, 0b100, 0b001, 0}, expSp: []UMask{0b101, 0b111}},
+		{m: 0b001, n: 3, expSub: []UMask{0b001, 0}, expSp: []UMask{0b001, 0b011, 0b101, 0b111}},
+		{m: 0b1000, n: 3, expSub: []UMask{0b1000, 0}, expSp: nil},
+		{m: Max &^ 1, n: BitCap, expSub: nil, expSp: []UMask{Max &^ 1, Max}},
+	}
+
+	for _, tc := range tcs {
+		if tc.expSub != nil {
+			if rec := slices.Collect(Submasks(tc.m)); !slices.Equal(tc.expSub, rec) {
+				t.Errorf("\nexpected %b\nreceived %b\n", tc.expSub, rec)
+			}
+		}
+
+		if rec := slices.Collect(Supersets(tc.m, tc.n)); !slices.Equal(tc.expSp, rec) {
+			t.Errorf("\nexpected %b\nreceived %b\n", tc.expSp, rec)
+		}
+	}
+
+	// Every submask is visited once.
+	m := Zero.SetBits(1, 4, 5, 9, 20)
+	var count int
+	for s := range Submasks(m) {
+		if !m.Masks(s) {
+			t.Errorf("\nexpected a submask of %b\nreceived %b\n", m, s)
+		}
+
+		count++
+	}
+
+	if exp := 1 << m.Count(); exp != count {
+		t.Errorf("\nexpected %d submasks\nreceived %d\n", exp, count)
+	}
+}
+
+func TestSubsetsPanic(t *testing.T) {
+	for _, n := range []int{-1, BitCap + 1} {
+		for name, f := range map[string]func(){
+			"Combinations": func() { Combinations(n, 0) },
+			"Supersets":    func() { Supersets(Zero, n) },
+		} {
+			func() {
+				defer func() {
+					if err, _ := recover().(error); !errors.Is(err, ErrBitOutOfRange) {
+						t.Errorf("\nexpected %v from %s for %d bits\nreceived %v\n", ErrBitOutOfRange, name, n, err)
+					}
+				}()
+
+				f()
+			}()
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package umask
 
 import (
+	"fmt"
+	"iter"
 	"math/bits"
 	"strconv"
 )
@@ -70,6 +72,66 @@ func (a UMask) XOr(b UMask) UMask {
 // Set functionality
 // -------------------------------------------------------------------------
 
+// All returns an iterator over the set bits in increasing order.
+func (a UMask) All() iter.Seq[int] {
+	return a.AllRange(0, BitCap)
+}
+
+// AllInRange determines if every bit on range [lo, hi) is set. An empty
+// range is always set.
+func (a UMask) AllInRange(lo, hi int) bool {
+	m := rangeMask(lo, hi)
+	return a&m == m
+}
+
+// AllRange returns an iterator over the set bits on range [lo, hi) in increasing order.
+func (a UMask) AllRange(lo, hi int) iter.Seq[int] {
+	if err := checkRange(lo, hi, BitCap); err != nil {
+		panic(err)
+	}
+
+	return func(yield func(int) bool) {
+		if hi <= lo {
+			return
+		}
+
+		for bit := a.NextBit(lo - 1); bit < hi; bit = a.NextBit(bit) {
+			if !yield(bit) {
+				return
+			}
+		}
+	}
+}
+
+// AnyInRange determines if any bit on range [lo, hi) is set.
+func (a UMask) AnyInRange(lo, hi int) bool {
+	return a&rangeMask(lo, hi) != 0
+}
+
+// Backward returns an iterator over the set bits in decreasing order.
+func (a UMask) Backward() iter.Seq[int] {
+	return a.BackwardRange(0, BitCap)
+}
+
+// BackwardRange returns an iterator over the set bits on range [lo, hi) in decreasing order.
+func (a UMask) BackwardRange(lo, hi int) iter.Seq[int] {
+	if err := checkRange(lo, hi, BitCap); err != nil {
+		panic(err)
+	}
+
+	return func(yield func(int) bool) {
+		if hi <= lo {
+			return
+		}
+
+		for bit := a.PrevBit(hi); lo <= bit; bit = a.PrevBit(bit) {
+			if !yield(bit) {
+				return
+			}
+		}
+	}
+}
+
 // BitLen returns the minimum number of bits representing a.
 func (a UMask) BitLen() int {
 	return bits.Len(uint(a))
@@ -104,16 +166,110 @@ func (a UMask) ClrBits(bits ...int) UMask {
 	return a
 }
 
+// ClrRange returns a bitmask with the bits on range [lo, hi) unset.
+func (a UMask) ClrRange(lo, hi int) UMask {
+	return a &^ rangeMask(lo, hi)
+}
+
 // Count returns the number of bits set in a bitmask.
 func (a UMask) Count() int {
 	return bits.OnesCount(uint(a))
 }
 
+// CountRange returns the number of bits set on range [lo, hi).
+func (a UMask) CountRange(lo, hi int) int {
+	return bits.OnesCount(uint(a & rangeMask(lo, hi)))
+}
+
+// Deposit returns the low bits of a scattered to the bits set in mask, in order. That is, the ith lowest bit of a is moved to the ith lowest set bit of mask. This is the PDEP instruction of BMI2.
+func (a UMask) Deposit(mask UMask) UMask {
+	var d UMask
+	for mask != 0 {
+		// Deposit a run of n set bits beginning at bit p at once.
+		p := bits.TrailingZeros(uint(mask))
+		n := bits.TrailingZeros(uint(^(mask >> p)))
+		d |= (a & (1<<n - 1)) << p
+		a >>= n
+		mask &^= (1<<n - 1) << p
+	}
+
+	return d
+}
+
+// Dice returns the Sørensen–Dice coefficient of a and b, which is twice the number of bits set in both divided by the sum of the numbers of bits set in each. If no bits are set in either, then it is one.
+func (a UMask) Dice(b UMask) float64 {
+	sum := a.Count() + b.Count()
+	if sum == 0 {
+		return 1
+	}
+
+	return float64(2*a.IntersectionCount(b)) / float64(sum)
+}
+
+// Extract returns the bits of a selected by mask gathered into the low bits, in order. That is, the bit of a at the ith lowest set bit of mask is moved to bit i. This is the PEXT instruction of BMI2.
+func (a UMask) Extract(mask UMask) UMask {
+	var (
+		e UMask
+		i int
+	)
+
+	for mask != 0 {
+		// Extract a run of n set bits beginning at bit p at once.
+		p := bits.TrailingZeros(uint(mask))
+		n := bits.TrailingZeros(uint(^(mask >> p)))
+		e |= (a >> p & (1<<n - 1)) << i
+		i += n
+		mask &^= (1<<n - 1) << p
+	}
+
+	return e
+}
+
+// FlipRange returns a bitmask with the bits on range [lo, hi) inverted.
+func (a UMask) FlipRange(lo, hi int) UMask {
+	return a ^ rangeMask(lo, hi)
+}
+
 // Fmt returns a representation of a bitmask in a given base on range [2, 36].
 func (a UMask) Fmt(base int) string {
 	return strconv.FormatUint(uint64(a), base)
 }
 
+// HammingDistance returns the number of bits set in exactly one of a and b.
+func (a UMask) HammingDistance(b UMask) int {
+	return bits.OnesCount(uint(a ^ b))
+}
+
+// IntersectionCount returns the number of bits set in both a and b.
+func (a UMask) IntersectionCount(b UMask) int {
+	return bits.OnesCount(uint(a & b))
+}
+
+// Intersects determines if any bit is set in both a and b.
+func (a UMask) Intersects(b UMask) bool {
+	return a&b != 0
+}
+
+// IsDisjoint determines if no bit is set in both a and b.
+func (a UMask) IsDisjoint(b UMask) bool {
+	return a&b == 0
+}
+
+// IsSubset determines if every bit set in a is set in b.
+func (a UMask) IsSubset(b UMask) bool {
+	return a&^b == 0
+}
+
+// Jaccard returns the Jaccard index of a and b, which is the number of bits set in both divided by the number of bits set in either. If no bits are set in either, then it is one.
+func (a UMask) Jaccard(b UMask) float64 {
+	or := a.UnionCount(b)
+	if or == 0 {
+		return 1
+	}
+
+	return float64(a.IntersectionCount(b)) / float64(or)
+}
+
 // LSh returns a bitmask with all bits shifted to the left a given number of bits.
 func (a UMask) LSh(bits int) UMask {
 	return a << bits
@@ -147,6 +303,26 @@ func (a UMask) RSh(bits int) UMask {
 	return a >> bits
 }
 
+// Reverse returns a bitmask with the order of its bits reversed. That is, bit i is moved to bit BitCap-1-i.
+func (a UMask) Reverse() UMask {
+	return UMask(bits.Reverse(uint(a)))
+}
+
+// ReverseBytes returns a bitmask with the order of its bytes reversed.
+func (a UMask) ReverseBytes() UMask {
+	return UMask(bits.ReverseBytes(uint(a)))
+}
+
+// RotL returns a bitmask with all bits rotated to the left k bits. A negative k rotates to the right.
+func (a UMask) RotL(k int) UMask {
+	return UMask(bits.RotateLeft(uint(a), k))
+}
+
+// RotR returns a bitmask with all bits rotated to the right k bits. A negative k rotates to the left.
+func (a UMask) RotR(k int) UMask {
+	return UMask(bits.RotateLeft(uint(a), -k))
+}
+
 // Set returns a bitmask with bits set in a or b.
 func (a UMask) Set(b UMask) UMask {
 	return a | b
@@ -166,6 +342,16 @@ func (a UMask) SetBits(bits ...int) UMask {
 	return a
 }
 
+// SetRange returns a bitmask with the bits on range [lo, hi) set.
+func (a UMask) SetRange(lo, hi int) UMask {
+	return a | rangeMask(lo, hi)
+}
+
+// UnionCount returns the number of bits set in either a or b.
+func (a UMask) UnionCount(b UMask) int {
+	return bits.OnesCount(uint(a | b))
+}
+
 // -------------------------------------------------------------------------
 // Helper functionality
 // -------------------------------------------------------------------------
@@ -181,3 +367,28 @@ func clamp(n, a, b int) int {
 		return n
 	}
 }
+
+// checkRange returns an error wrapping ErrBitOutOfRange if a non-empty
+// range [lo, hi) is not within [0, bitCap).
+func checkRange(lo, hi, bitCap int) error {
+	if lo < hi && (lo < 0 || bitCap < hi) {
+		return fmt.Errorf("%w: [%d, %d) not within [0, %d)", ErrBitOutOfRange, lo, hi, bitCap)
+	}
+
+	return nil
+}
+
+// rangeMask returns a bitmask with the bits on range [lo, hi) set. It
+// panics if checkRange fails.
+func rangeMask(lo, hi int) UMask {
+	if err := checkRange(lo, hi, BitCap); err != nil {
+		panic(err)
+	}
+
+	lo, hi = clamp(lo, 0, BitCap), clamp(hi, 0, BitCap)
+	if hi <= lo {
+		return Zero
+	}
+
+	return Max >> (BitCap - hi + lo) << lo
+}
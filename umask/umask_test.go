@@ -1,7 +1,9 @@
 package umask
 
 import (
+	"errors"
 	"math"
+	"slices"
 	"testing"
 )
 
@@ -34,6 +36,73 @@ func TestAnd(t *testing.T) {
 	}
 }
 
+func TestAllBackward(t *testing.T) {
+	type testCase struct {
+		a           UMask
+		lo, hi      int
+		expAll      []int
+		expBackward []int
+	}
+
+	tcs := []testCase{
+		{
+			a:           Zero,
+			lo:          0,
+			hi:          BitCap,
+			expAll:      []int{},
+			expBackward: []int{},
+		},
+		{
+			a:           Zero.SetBits(0, 1, BitCap-1),
+			lo:          0,
+			hi:          BitCap,
+			expAll:      []int{0, 1, BitCap - 1},
+			expBackward: []int{BitCap - 1, 1, 0},
+		},
+		{
+			a:           Zero.SetBits(0, 1, BitCap-1),
+			lo:          1,
+			hi:          BitCap - 1,
+			expAll:      []int{1},
+			expBackward: []int{1},
+		},
+		{
+			a:           Max,
+			lo:          -5,
+			hi:          -10,
+			expAll:      []int{},
+			expBackward: []int{},
+		},
+		{
+			a:           Max,
+			lo:          BitCap + 5,
+			hi:          BitCap + 2,
+			expAll:      []int{},
+			expBackward: []int{},
+		},
+	}
+
+	for _, tc := range tcs {
+		if rec := slices.Collect(tc.a.AllRange(tc.lo, tc.hi)); !slices.Equal(tc.expAll, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", tc.expAll, rec)
+		}
+
+		if rec := slices.Collect(tc.a.BackwardRange(tc.lo, tc.hi)); !slices.Equal(tc.expBackward, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", tc.expBackward, rec)
+		}
+
+		if exp, rec := tc.a.Bits(), slices.Collect(tc.a.All()); !slices.Equal(exp, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+
+		exp := tc.a.Bits()
+		slices.Reverse(exp)
+		if rec := slices.Collect(tc.a.Backward()); !slices.Equal(exp, rec) {
+			t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+		}
+	}
+}
+
 func TestBitLen(t *testing.T) {
 	var exp int // Iterates over range [0,BitCap]
 	if rec := Zero.BitLen(); exp != rec {
@@ -362,6 +431,200 @@ func TestOr(t *testing.T) {
 	}
 }
 
+func TestRange(t *testing.T) {
+	var (
+		a    = Zero.SetBits(0, 2, 3, BitCap/2, BitCap/2+1, BitCap-1)
+		ends = []int{-1, 0, 1, 3, BitCap / 2, BitCap - 1, BitCap, BitCap + 1}
+	)
+
+	for _, lo := range ends {
+		for _, hi := range ends {
+			if lo < hi && (lo < 0 || BitCap < hi) {
+				for name, f := range map[string]func(){
+					"AllInRange":    func() { a.AllInRange(lo, hi) },
+					"AllRange":      func() { a.AllRange(lo, hi) },
+					"AnyInRange":    func() { a.AnyInRange(lo, hi) },
+					"BackwardRange": func() { a.BackwardRange(lo, hi) },
+					"ClrRange":      func() { a.ClrRange(lo, hi) },
+					"CountRange":    func() { a.CountRange(lo, hi) },
+					"FlipRange":     func() { a.FlipRange(lo, hi) },
+					"SetRange":      func() { a.SetRange(lo, hi) },
+				} {
+					func() {
+						defer func() {
+							if err, _ := recover().(error); !errors.Is(err, ErrBitOutOfRange) {
+								t.Errorf("\nexpected %v from %s on [%d, %d)\nreceived %v\n", ErrBitOutOfRange, name, lo, hi, err)
+							}
+						}()
+
+						f()
+					}()
+				}
+
+				continue
+			}
+
+			var (
+				count                 int
+				setExp, clrExp, flExp = a, a, a
+				anyExp, allExp        = false, true
+			)
+
+			for bit := lo; bit < hi; bit++ {
+				if a.MasksBit(bit) {
+					count++
+					anyExp = true
+				} else {
+					allExp = false
+				}
+
+				setExp = setExp.SetBit(bit)
+				clrExp = clrExp.ClrBit(bit)
+				flExp ^= 1 << bit
+			}
+
+			if rec := a.CountRange(lo, hi); count != rec {
+				t.Errorf("\nexpected count %d on [%d, %d)\nreceived %d\n", count, lo, hi, rec)
+			}
+
+			if rec := a.AnyInRange(lo, hi); anyExp != rec {
+				t.Errorf("\nexpected any %t on [%d, %d)\nreceived %t\n", anyExp, lo, hi, rec)
+			}
+
+			if rec := a.AllInRange(lo, hi); allExp != rec {
+				t.Errorf("\nexpected all %t on [%d, %d)\nreceived %t\n", allExp, lo, hi, rec)
+			}
+
+			if rec := a.SetRange(lo, hi); setExp != rec {
+				t.Errorf("\nexpected %b on [%d, %d)\nreceived %b\n", setExp, lo, hi, rec)
+			}
+
+			if rec := a.ClrRange(lo, hi); clrExp != rec {
+				t.Errorf("\nexpected %b on [%d, %d)\nreceived %b\n", clrExp, lo, hi, rec)
+			}
+
+			if rec := a.FlipRange(lo, hi); flExp != rec {
+				t.Errorf("\nexpected %b on [%d, %d)\nreceived %b\n", flExp, lo, hi, rec)
+			}
+		}
+	}
+}
+
+func TestRotReverse(t *testing.T) {
+	for _, a := range []UMask{Zero, One, 0xf0, One<<(BitCap-1) | 5, Max, UMask(0x0102030405060708 & uint64(Max))} {
+		for _, k := range []int{-BitCap - 1, -1, 0, 1, 3, BitCap - 1, BitCap, 2*BitCap + 1} {
+			expL, expR := Zero, Zero
+			for bit := 0; bit < BitCap; bit++ {
+				if a.MasksBit(bit) {
+					expL |= 1 << (((bit+k)%BitCap + BitCap) % BitCap)
+					expR |= 1 << (((bit-k)%BitCap + BitCap) % BitCap)
+				}
+			}
+
+			if rec := a.RotL(k); expL != rec {
+				t.Errorf("\nexpected %b\nreceived %b\n", expL, rec)
+			}
+
+			if rec := a.RotR(k); expR != rec {
+				t.Errorf("\nexpected %b\nreceived %b\n", expR, rec)
+			}
+		}
+
+		expRev, expBytes := Zero, Zero
+		for bit := 0; bit < BitCap; bit++ {
+			if a.MasksBit(bit) {
+				expRev |= 1 << (BitCap - 1 - bit)
+				expBytes |= 1 << (BitCap - 8 - bit/8*8 + bit%8)
+			}
+		}
+
+		if rec := a.Reverse(); expRev != rec {
+			t.Errorf("\nexpected %b\nreceived %b\n", expRev, rec)
+		}
+
+		if rec := a.ReverseBytes(); expBytes != rec {
+			t.Errorf("\nexpected %b\nreceived %b\n", expBytes, rec)
+		}
+
+		if exp, rec := a.LSh(3), a.RotL(3)&^7; exp != rec {
+			t.Errorf("\nexpected %b\nreceived %b\n", exp, rec)
+		}
+	}
+}
+
+func TestExtractDeposit(t *testing.T) {
+	vals := []UMask{Zero, One, 0xf0, One<<(BitCap-1) | 5, Max, 0x9abcdef0 & UMask(uint64(Max)), Max &^ 0x1000}
+	for _, a := range vals {
+		for _, m := range vals {
+			// Move bits one at a time.
+			expExt, expDep := Zero, Zero
+			for bit, i := 0, 0; bit < BitCap; bit++ {
+				if m.MasksBit(bit) {
+					if a.MasksBit(bit) {
+						expExt |= 1 << i
+					}
+
+					if a.MasksBit(i) {
+						expDep |= 1 << bit
+					}
+
+					i++
+				}
+			}
+
+			if rec := a.Extract(m); expExt != rec {
+				t.Errorf("\nexpected %b\nreceived %b\n", expExt, rec)
+			}
+
+			if rec := a.Deposit(m); expDep != rec {
+				t.Errorf("\nexpected %b\nreceived %b\n", expDep, rec)
+			}
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	vals := []UMask{Zero, One, 0xf0, 0x3c, One<<(BitCap-1) | 5, Max}
+	for _, a := range vals {
+		for _, b := range vals {
+			and, or := (a & b).Count(), (a | b).Count()
+			if exp, rec := (a ^ b).Count(), a.HammingDistance(b); exp != rec {
+				t.Errorf("\nexpected %d\nreceived %d\n", exp, rec)
+			}
+
+			if rec := a.IntersectionCount(b); and != rec {
+				t.Errorf("\nexpected %d\nreceived %d\n", and, rec)
+			}
+
+			if rec := a.UnionCount(b); or != rec {
+				t.Errorf("\nexpected %d\nreceived %d\n", or, rec)
+			}
+
+			if exp, rec := a&b != 0, a.Intersects(b); exp != rec || exp == a.IsDisjoint(b) {
+				t.Errorf("\nexpected intersects %t\nreceived %t\n", exp, rec)
+			}
+
+			if exp, rec := a|b == b, a.IsSubset(b); exp != rec {
+				t.Errorf("\nexpected subset %t\nreceived %t\n", exp, rec)
+			}
+
+			expJ, expD := 1.0, 1.0
+			if 0 < or {
+				expJ = float64(and) / float64(or)
+				expD = float64(2*and) / float64((a).Count()+(b).Count())
+			}
+
+			if rec := a.Jaccard(b); expJ != rec {
+				t.Errorf("\nexpected %f\nreceived %f\n", expJ, rec)
+			}
+
+			if rec := a.Dice(b); expD != rec {
+				t.Errorf("\nexpected %f\nreceived %f\n", expD, rec)
+			}
+		}
+	}
+}
+
 func TestXOr(t *testing.T) {
 	type testCase struct {
 		a, b, exp UMask